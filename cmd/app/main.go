@@ -15,12 +15,12 @@ func main() {
 		fmt.Println("Aviso: Não foi possível carregar o arquivo .env. Usando variáveis de ambiente do sistema.")
 	}
 
-	database, err := db.InitDB("sqlite", "test.db")
+	database, err := db.InitDB("sqlite", "test.db?_busy_timeout=5000")
 	if err != nil {
 		panic("Falha ao conectar ao banco de dados: " + err.Error())
 	}
 
-	err = database.AutoMigrate(&models.User{}, &models.Robot{}, &models.Plan{}, &models.ConversaLog{}, &models.Payment{}, &models.Subscription{})
+	err = database.AutoMigrate(&models.User{}, &models.Robot{}, &models.Plan{}, &models.ConversaLog{}, &models.Payment{}, &models.Subscription{}, &models.StripeEvent{}, &models.PlanCatalog{}, &models.MessageUsage{}, &models.ConversaSession{}, &models.RobotProfile{}, &models.Emotion{}, &models.EmotionAnimation{}, &models.ModelPrice{}, &models.QuotaReservation{}, &models.OutboxMessage{}, &models.RobotPresenceEvent{}, &models.TelemetryPoint{}, &models.RobotCommand{}, &models.Device{}, &models.DeviceAuthorization{}, &models.RobotToken{})
 	if err != nil {
 		panic("Falha ao migrar o banco de dados: " + err.Error())
 	}
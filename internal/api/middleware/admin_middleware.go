@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+// AdminMiddleware exige que o usuário autenticado (AuthMiddleware) seja administrador
+func AdminMiddleware(userService services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get("user_id")
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+			c.Abort()
+			return
+		}
+
+		user, err := userService.FindByID(userID.(string))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user data"})
+			c.Abort()
+			return
+		}
+
+		if user == nil || user.Role != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,18 +1,16 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-	"github.com/peruccii/roadmap-go-backend/internal/repository"
 	"github.com/peruccii/roadmap-go-backend/internal/services"
 )
 
 // RoboAuthMiddleware verifica um token JWT que representa um robô e se sua assinatura está ativa
-func RoboAuthMiddleware(authService services.AuthService, subscriptionRepo repository.SubscriptionRepository, robotRepo repository.RobotRepository) gin.HandlerFunc {
+func RoboAuthMiddleware(authorizer services.RobotAuthorizer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -28,60 +26,45 @@ func RoboAuthMiddleware(authService services.AuthService, subscriptionRepo repos
 			return
 		}
 
-		claims, err := authService.VerifyToken(tokenString)
+		authorization, err := authorizer.Authorize(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			status, message := robotAuthErrorResponse(err)
+			c.JSON(status, gin.H{"error": message})
 			c.Abort()
 			return
 		}
 
-		// Extrair robo_id do token
-		roboIDStr, ok := claims["robo_id"].(string)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing 'robo_id' in token"})
-			c.Abort()
-			return
-		}
-
-		roboID, err := uuid.Parse(roboIDStr)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid robot ID format"})
-			c.Abort()
-			return
-		}
-
-		// Verificar se o robô existe e está ativo
-		robot, err := robotRepo.FindById(roboID)
-		if err != nil || robot == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Robot not found"})
-			c.Abort()
-			return
-		}
-
-		// Verificar se o robô está ativo
-		if robot.Status != "active" {
-			c.JSON(http.StatusPaymentRequired, gin.H{"error": "Robot is not active. Please check your subscription"})
-			c.Abort()
-			return
-		}
-
-		// Verificar assinatura ativa
-		subscription, err := subscriptionRepo.FindActiveByRobotID(roboID)
-		if err != nil || subscription == nil || !subscription.IsActive() {
-			// Se não há assinatura ativa, verificar se ainda está no período de validade do plano
-			if robot.PlanValidUntil == nil || robot.PlanValidUntil.Before(time.Now()) {
-				c.JSON(http.StatusPaymentRequired, gin.H{"error": "Subscription expired. Please renew your plan"})
-				c.Abort()
-				return
-			}
-		}
-
 		// Se chegou até aqui, o robô está autorizado
-		c.Set("robo_id", roboIDStr)
-		c.Set("robot", robot)
-		if subscription != nil {
-			c.Set("subscription", subscription)
+		c.Set("robo_id", authorization.RobotID.String())
+		c.Set("robot", authorization.Robot)
+		if authorization.TokenID != nil {
+			c.Set("robot_token_id", authorization.TokenID.String())
+		}
+		if authorization.Subscription != nil {
+			c.Set("subscription", authorization.Subscription)
 		}
 		c.Next()
 	}
 }
+
+// robotAuthErrorResponse traduz uma falha do RobotAuthorizer no status e na mensagem da API
+func robotAuthErrorResponse(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidRobotToken):
+		return http.StatusUnauthorized, "Invalid token"
+	case errors.Is(err, services.ErrRobotTokenRevoked):
+		return http.StatusUnauthorized, "Token has been revoked"
+	case errors.Is(err, services.ErrMissingRobotID):
+		return http.StatusUnauthorized, "Invalid or missing 'robo_id' in token"
+	case errors.Is(err, services.ErrInvalidRobotIDFormat):
+		return http.StatusUnauthorized, "Invalid robot ID format"
+	case errors.Is(err, services.ErrRobotNotFound):
+		return http.StatusNotFound, "Robot not found"
+	case errors.Is(err, services.ErrRobotInactive):
+		return http.StatusPaymentRequired, "Robot is not active. Please check your subscription"
+	case errors.Is(err, services.ErrRobotSubscriptionExpired):
+		return http.StatusPaymentRequired, "Subscription expired. Please renew your plan"
+	default:
+		return http.StatusInternalServerError, err.Error()
+	}
+}
@@ -1,6 +1,10 @@
 package api
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/peruccii/roadmap-go-backend/internal/api/middleware"
 	"github.com/peruccii/roadmap-go-backend/internal/controller"
@@ -18,15 +22,51 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 	robotRepo := repository.NewRobotRepository(db)
 	paymentRepo := repository.NewPaymentRepository(db)
 	subscriptionRepo := repository.NewSubscriptionRepository(db)
+	stripeEventRepo := repository.NewStripeEventRepository(db)
+	planCatalogRepo := repository.NewPlanCatalogRepository(db)
+	messageUsageRepo := repository.NewMessageUsageRepository(db)
+	conversaRepo := repository.NewConversaRepository(db)
+	robotProfileRepo := repository.NewRobotProfileRepository(db)
+	emotionRepo := repository.NewEmotionRepository(db)
+	modelPriceRepo := repository.NewModelPriceRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	presenceRepo := repository.NewPresenceRepository(db)
+	telemetryRepo := repository.NewTelemetryRepository(db)
+	robotCommandRepo := repository.NewRobotCommandRepository(db)
+	deviceRepo := repository.NewDeviceRepository(db)
+	deviceAuthorizationRepo := repository.NewDeviceAuthorizationRepository(db)
+	robotTokenRepo := repository.NewRobotTokenRepository(db)
 
 	// Serviços
 	authService := services.NewAuthService(userRepo)
 	userService := services.NewUserService(userRepo)
 	planService := services.NewPlanService(planRepo)
-	paymentService := services.NewPaymentService(paymentRepo, robotRepo)
-	stripeService := services.NewStripeService(paymentRepo, subscriptionRepo, robotRepo, paymentService)
-	robotService := services.NewRobotService(robotRepo, planService)
-	iaService := services.NewIAService()
+	planCatalogService := services.NewPlanCatalogService(planCatalogRepo)
+	subscriptionService := services.NewSubscriptionService(subscriptionRepo, robotRepo)
+	paymentService := services.NewPaymentService(paymentRepo, robotRepo, subscriptionRepo, subscriptionService)
+	stripeService := services.NewStripeService(paymentRepo, subscriptionRepo, robotRepo, stripeEventRepo, paymentService, subscriptionService, planCatalogService)
+	robotHub := services.NewRobotHub()
+	robotAuthorizer := services.NewRobotAuthorizer(authService, subscriptionRepo, robotRepo, robotTokenRepo)
+	robotService := services.NewRobotService(robotRepo, planService, robotHub, robotTokenRepo)
+	llmProvider, err := services.NewLLMProviderFromEnv()
+	if err != nil {
+		panic("Falha ao configurar o provedor de IA: " + err.Error())
+	}
+	llmProvider = services.NewResilientProvider(llmProvider, services.ResilienceConfigFromEnv())
+	iaService := services.NewIAService(llmProvider, services.IAFallbackFromEnv())
+	usageService := services.NewUsageService(messageUsageRepo, subscriptionRepo, planRepo, planCatalogRepo)
+	conversaService := services.NewConversaService(conversaRepo, robotRepo)
+	emotionService := services.NewEmotionService(emotionRepo)
+	personaService := services.NewPersonaService(robotProfileRepo, robotRepo, emotionService)
+	costService := services.NewCostService(modelPriceRepo)
+	reportService := services.NewReportService(reportRepo)
+	outboxService := services.NewOutboxService(outboxRepo)
+	presenceService := services.NewPresenceService(presenceRepo, robotRepo)
+	telemetryService := services.NewTelemetryService(telemetryRepo, robotRepo, usageService)
+	robotCommandService := services.NewRobotCommandService(robotCommandRepo, robotRepo, robotHub)
+	deviceService := services.NewDeviceService(deviceRepo, robotRepo, robotService, robotAuthorizer)
+	deviceAuthorizationService := services.NewDeviceAuthorizationService(deviceAuthorizationRepo, robotRepo, robotService, robotAuthorizer)
 
 	// Controladores
 	authController := controller.NewAuthController(authService)
@@ -34,7 +74,39 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 	robotController := controller.NewRobotController(robotService)
 	paymentController := controller.NewPaymentController(stripeService, userService)
 	stripeController := controller.NewStripeController(stripeService)
-	conversaController := controller.NewConversaController(db, iaService)
+	subscriptionController := controller.NewSubscriptionController(subscriptionService, stripeService, usageService)
+	planCatalogController := controller.NewPlanCatalogController(planCatalogService)
+	personaController := controller.NewPersonaController(personaService)
+	emotionController := controller.NewEmotionController(emotionService)
+	reportController := controller.NewReportController(costService, reportService)
+	outboxController := controller.NewOutboxController(outboxService)
+	presenceController := controller.NewPresenceController(presenceService)
+	telemetryController := controller.NewTelemetryController(telemetryService)
+	robotCommandController := controller.NewRobotCommandController(robotCommandService)
+	robotChannelController := controller.NewRobotChannelController(robotHub, robotAuthorizer, robotCommandService, robotService, services.RobotChannelConfigFromEnv())
+	deviceController := controller.NewDeviceController(deviceService)
+	deviceAuthorizationController := controller.NewDeviceAuthorizationController(deviceAuthorizationService)
+	robotTokenController := controller.NewRobotTokenController(robotService)
+
+	if err := planCatalogService.SeedDefaults(); err != nil {
+		fmt.Println("Aviso: não foi possível criar os planos padrão:", err)
+	}
+	if err := emotionService.SeedDefaults(); err != nil {
+		fmt.Println("Aviso: não foi possível criar as emoções padrão:", err)
+	}
+	if err := costService.SeedDefaults(); err != nil {
+		fmt.Println("Aviso: não foi possível criar os preços padrão dos modelos de IA:", err)
+	}
+	conversaController := controller.NewConversaController(db, iaService, usageService, conversaService, personaService, emotionService, costService, outboxService)
+
+	// Workers em segundo plano
+	go subscriptionService.RunDunningWorker(context.Background(), 10*time.Minute)
+	go usageService.RunReservationSweeper(context.Background(), time.Minute)
+	go outboxService.RunDispatcher(context.Background(), 2*time.Second)
+	go presenceService.RunPresenceSweeper(context.Background())
+	go telemetryService.RunRetentionWorker(context.Background(), time.Hour)
+	go robotCommandService.RunExpirySweeper(context.Background(), 30*time.Second)
+	go deviceAuthorizationService.RunPurgeWorker(context.Background(), time.Hour)
 
 	api := r.Group("/api")
 	{
@@ -44,8 +116,19 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 			auth.POST("/login", authController.Login)
 		}
 
+		// Catálogo público de planos
+		api.GET("/plans", planCatalogController.FindActive)
+
+		// Bootstrap do dispositivo físico (autenticado pelo segredo de fábrica)
+		api.POST("/devices/bootstrap", deviceController.Bootstrap)
+
+		// Pareamento por autorização de dispositivo: o robô pede o código e consulta o token
+		api.POST("/devices/authorize", deviceAuthorizationController.Authorize)
+		api.POST("/devices/token", deviceAuthorizationController.Token)
+
 	// Grupo protegido por autenticação de usuário
-	protected := api.Group("").Use(middleware.AuthMiddleware(authService))
+	protected := api.Group("")
+	protected.Use(middleware.AuthMiddleware(authService))
 	{
 		// Endpoints de pagamento (substituem a criação direta de robôs)
 		payments := protected.Group("/payments")
@@ -57,23 +140,97 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 		// Endpoints de robôs (sem criação direta)
 		robots := protected.Group("/robots")
 		{
-			robots.GET("/:name", robotController.FindByName)
+			// O gin exige o mesmo nome de wildcard no segmento, então a busca por nome também usa :id
+			robots.GET("/:id", robotController.FindByName)
 			robots.POST("/:id/token", robotController.GenerateToken)
+			robots.GET("/:id/tokens", robotTokenController.FindAll)
+			robots.POST("/:id/tokens/:token_id/revoke", robotTokenController.Revoke)
+			robots.PUT("/:id/settings", robotController.UpdateSettings)
+			robots.GET("/:id/presence", presenceController.History)
+			robots.GET("/:id/telemetry", telemetryController.Query)
+			robots.POST("/:id/commands", robotCommandController.Create)
+			robots.GET("/:id/commands", robotCommandController.FindAll)
+			robots.GET("/:id/commands/:command_id", robotCommandController.FindByID)
+			robots.POST("/:id/speech", robotChannelController.Speak)
+			robots.POST("/:id/device", deviceController.Claim)
+			robots.GET("/:id/device", deviceController.Find)
+			robots.DELETE("/:id/device", deviceController.Release)
+			robots.POST("/:id/pair", deviceAuthorizationController.Approve)
+			robots.POST("/:id/conversa/session", conversaController.NewSessionForRobot)
+			robots.GET("/:id/persona", personaController.Find)
+			robots.PUT("/:id/persona", personaController.Update)
+			robots.DELETE("/:id/persona", personaController.Reset)
 			robots.GET("", robotController.FindAll)
 		}
 
+		// Endpoints de assinaturas do usuário
+		subscriptions := protected.Group("/subscriptions")
+		{
+			subscriptions.GET("", subscriptionController.FindAll)
+			subscriptions.POST("/:id/cancel", subscriptionController.Cancel)
+			subscriptions.POST("/:id/resume", subscriptionController.Resume)
+			subscriptions.POST("/:id/change-plan", subscriptionController.ChangePlan)
+			subscriptions.GET("/:id/usage", subscriptionController.Usage)
+		}
+
 		// Endpoints de usuários
 		users := protected.Group("/users")
 		{
 			users.GET("", userController.FindAll)
 		}
+
+		// Endpoints administrativos
+		admin := protected.Group("/admin")
+		admin.Use(middleware.AdminMiddleware(userService))
+		{
+			admin.GET("/stripe/events", stripeController.ListEvents)
+			admin.GET("/stripe/events/:id", stripeController.FindEvent)
+			admin.POST("/stripe/events/:id/replay", stripeController.ReplayEvent)
+
+			admin.GET("/plans", planCatalogController.FindAll)
+			admin.POST("/plans", planCatalogController.Create)
+			admin.PUT("/plans/:code", planCatalogController.Update)
+			admin.DELETE("/plans/:code", planCatalogController.Delete)
+
+			admin.GET("/emotions", emotionController.FindAll)
+			admin.POST("/emotions", emotionController.Create)
+			admin.PUT("/emotions/:id", emotionController.Update)
+			admin.DELETE("/emotions/:id", emotionController.Delete)
+			admin.PUT("/emotions/:id/animations/:model", emotionController.SetAnimation)
+			admin.DELETE("/emotions/:id/animations/:model", emotionController.DeleteAnimation)
+
+			admin.GET("/ai/prices", reportController.FindPrices)
+			admin.PUT("/ai/prices/:model", reportController.SetPrice)
+			admin.DELETE("/ai/prices/:model", reportController.DeletePrice)
+
+			admin.GET("/reports/ai-usage", reportController.AIUsage)
+			admin.GET("/reports/plans", reportController.PlanRevenue)
+
+			admin.GET("/outbox", outboxController.FindAll)
+			admin.GET("/outbox/:id", outboxController.FindByID)
+			admin.POST("/outbox/:id/retry", outboxController.Retry)
+
+			admin.GET("/devices", deviceController.FindAll)
+			admin.POST("/devices/import", deviceController.Import)
+			admin.POST("/devices/:serial/unlock", deviceController.UnlockClaim)
+		}
 	}
 
 	// Webhook do Stripe (sem autenticação)
 	api.POST("/stripe/webhook", stripeController.StripeWebhookController)
 
 	// Endpoint de conversa (protegido por autenticação de robô)
-	api.POST("/conversa", middleware.RoboAuthMiddleware(authService, subscriptionRepo, robotRepo), conversaController.Conversa)
+	roboAuth := middleware.RoboAuthMiddleware(robotAuthorizer)
+	api.POST("/conversa", roboAuth, conversaController.Conversa)
+	api.POST("/conversa/stream", roboAuth, conversaController.ConversaStream)
+	api.GET("/conversa/usage", roboAuth, conversaController.Usage)
+	api.POST("/conversa/session", roboAuth, conversaController.NewSession)
+	api.POST("/robot/heartbeat", roboAuth, presenceController.Heartbeat)
+	api.POST("/robot/telemetry", roboAuth, telemetryController.Ingest)
+	api.GET("/robot/commands", roboAuth, robotCommandController.Poll)
+	api.POST("/robot/commands/:id/ack", roboAuth, robotCommandController.Ack)
+	api.GET("/robot/ws", roboAuth, robotChannelController.Connect)
+	api.POST("/robot/token/rotate", roboAuth, robotTokenController.Rotate)
 	}
 
 	return r
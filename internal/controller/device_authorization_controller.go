@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+// DeviceAuthorizationController expõe o pareamento por autorização de dispositivo (RFC 8628)
+type DeviceAuthorizationController interface {
+	Authorize(c *gin.Context)
+	Token(c *gin.Context)
+	Approve(c *gin.Context)
+}
+
+type deviceAuthorizationController struct {
+	service services.DeviceAuthorizationService
+}
+
+func NewDeviceAuthorizationController(service services.DeviceAuthorizationService) DeviceAuthorizationController {
+	return &deviceAuthorizationController{service: service}
+}
+
+// Authorize abre um pedido de pareamento; o robô mostra o user_code ao dono
+func (ctrl *deviceAuthorizationController) Authorize(c *gin.Context) {
+	var input dtos.DeviceAuthorizeInputDTO
+	// Corpo vazio é aceito: o serial é só informativo
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+			return
+		}
+	}
+
+	result, err := ctrl.service.Authorize(input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Token é consultado pelo robô até o dono aprovar. Enquanto isso responde 400
+// com os códigos da RFC 8628 (authorization_pending, slow_down, expired_token, invalid_grant).
+func (ctrl *deviceAuthorizationController) Token(c *gin.Context) {
+	var input dtos.DeviceTokenInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	result, err := ctrl.service.Token(input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAuthorizationPending), errors.Is(err, services.ErrPollingTooFast),
+			errors.Is(err, services.ErrDeviceCodeExpired), errors.Is(err, services.ErrInvalidDeviceCode):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrDeviceRobotUnavailable):
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Approve pareia o user_code mostrado pelo robô com um robô do usuário
+func (ctrl *deviceAuthorizationController) Approve(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var input dtos.ApproveDevicePairingInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	authorization, err := ctrl.service.Approve(c.Param("id"), userID.(string), input)
+	if err != nil {
+		if errors.Is(err, services.ErrRobotNotFound) || errors.Is(err, services.ErrInvalidUserCode) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, authorization)
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+// DeviceController expõe a importação de dispositivos (admin), o vínculo pelo
+// dono e o bootstrap feito pelo próprio dispositivo
+type DeviceController interface {
+	Import(c *gin.Context)
+	FindAll(c *gin.Context)
+	UnlockClaim(c *gin.Context)
+	Claim(c *gin.Context)
+	Find(c *gin.Context)
+	Release(c *gin.Context)
+	Bootstrap(c *gin.Context)
+}
+
+type deviceController struct {
+	service services.DeviceService
+}
+
+func NewDeviceController(service services.DeviceService) DeviceController {
+	return &deviceController{service: service}
+}
+
+// Import cadastra um lote de dispositivos; códigos e segredos só aparecem nesta resposta
+func (ctrl *deviceController) Import(c *gin.Context) {
+	var input dtos.ImportDevicesInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	devices, err := ctrl.service.Import(input)
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, devices)
+}
+
+// FindAll lista os dispositivos cadastrados (?status=unclaimed|claimed)
+func (ctrl *deviceController) FindAll(c *gin.Context) {
+	devices, err := ctrl.service.FindAll(models.DeviceStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, devices)
+}
+
+// UnlockClaim libera o vínculo de um dispositivo bloqueado por códigos errados
+func (ctrl *deviceController) UnlockClaim(c *gin.Context) {
+	device, err := ctrl.service.UnlockClaim(c.Param("serial"))
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, device)
+}
+
+// Claim vincula o dispositivo (serial + código da caixa) a um robô do usuário
+func (ctrl *deviceController) Claim(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var input dtos.ClaimDeviceInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	device, err := ctrl.service.Claim(c.Param("id"), userID.(string), input)
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, device)
+}
+
+func (ctrl *deviceController) Find(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	device, err := ctrl.service.FindForOwner(c.Param("id"), userID.(string))
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, device)
+}
+
+func (ctrl *deviceController) Release(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	if err := ctrl.service.Release(c.Param("id"), userID.(string)); err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Bootstrap troca serial + segredo do dispositivo por um token do robô vinculado
+func (ctrl *deviceController) Bootstrap(c *gin.Context) {
+	var input dtos.DeviceBootstrapInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	result, err := ctrl.service.Bootstrap(input)
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func respondDeviceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDuplicateDeviceSerial):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidDeviceSecret):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDeviceRobotUnavailable):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRobotNotFound), errors.Is(err, services.ErrDeviceNotFound), errors.Is(err, services.ErrInvalidClaimCode),
+		errors.Is(err, services.ErrUnknownDeviceSerial):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDeviceSerialExists), errors.Is(err, services.ErrDeviceAlreadyClaimed),
+		errors.Is(err, services.ErrRobotHasDevice), errors.Is(err, services.ErrDeviceNotClaimed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDeviceClaimLocked):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
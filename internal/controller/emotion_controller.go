@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+type EmotionController interface {
+	FindAll(c *gin.Context)
+	Create(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	SetAnimation(c *gin.Context)
+	DeleteAnimation(c *gin.Context)
+}
+
+type emotionController struct {
+	service services.EmotionService
+}
+
+func NewEmotionController(service services.EmotionService) EmotionController {
+	return &emotionController{service: service}
+}
+
+func (ctrl *emotionController) FindAll(c *gin.Context) {
+	emotions, err := ctrl.service.FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, emotions)
+}
+
+func (ctrl *emotionController) Create(c *gin.Context) {
+	var input dtos.CreateEmotionInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	emotion, err := ctrl.service.Create(input)
+	if err != nil {
+		respondEmotionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, emotion)
+}
+
+func (ctrl *emotionController) Update(c *gin.Context) {
+	var input dtos.UpdateEmotionInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	emotion, err := ctrl.service.Update(c.Param("id"), input)
+	if err != nil {
+		respondEmotionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, emotion)
+}
+
+func (ctrl *emotionController) Delete(c *gin.Context) {
+	if err := ctrl.service.Delete(c.Param("id")); err != nil {
+		respondEmotionError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetAnimation cria ou substitui a animação da emoção para um modelo de robô
+func (ctrl *emotionController) SetAnimation(c *gin.Context) {
+	var input dtos.EmotionAnimationInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	animation, err := ctrl.service.SetAnimation(c.Param("id"), c.Param("model"), input)
+	if err != nil {
+		respondEmotionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, animation)
+}
+
+func (ctrl *emotionController) DeleteAnimation(c *gin.Context) {
+	if err := ctrl.service.DeleteAnimation(c.Param("id"), c.Param("model")); err != nil {
+		respondEmotionError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondEmotionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrEmotionNotFound), errors.Is(err, services.ErrEmotionAnimationMissing):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEmotionAlreadyExists), errors.Is(err, services.ErrDefaultEmotionRequired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,13 +1,13 @@
 package controller
 
 import (
-	"bytes"
-	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/peruccii/roadmap-go-backend/internal/dtos"
 	"github.com/peruccii/roadmap-go-backend/internal/models"
 	"github.com/peruccii/roadmap-go-backend/internal/services"
@@ -15,14 +15,26 @@ import (
 )
 
 type ConversaController struct {
-	DB        *gorm.DB
-	IAService services.IAServiceInterface
+	DB              *gorm.DB
+	IAService       services.IAServiceInterface
+	UsageService    services.UsageService
+	ConversaService services.ConversaService
+	PersonaService  services.PersonaService
+	EmotionService  services.EmotionService
+	CostService     services.CostService
+	OutboxService   services.OutboxService
 }
 
-func NewConversaController(db *gorm.DB, iaService services.IAServiceInterface) *ConversaController {
+func NewConversaController(db *gorm.DB, iaService services.IAServiceInterface, usageService services.UsageService, conversaService services.ConversaService, personaService services.PersonaService, emotionService services.EmotionService, costService services.CostService, outboxService services.OutboxService) *ConversaController {
 	return &ConversaController{
-		DB:        db,
-		IAService: iaService,
+		DB:              db,
+		IAService:       iaService,
+		UsageService:    usageService,
+		ConversaService: conversaService,
+		PersonaService:  personaService,
+		EmotionService:  emotionService,
+		CostService:     costService,
+		OutboxService:   outboxService,
 	}
 }
 
@@ -44,126 +56,378 @@ func (ctrl *ConversaController) Conversa(c *gin.Context) {
 		return
 	}
 
-	var respostaIA string
-	var emocaoIA string
-	var err error
+	roboID, err := uuid.Parse(roboIDStr)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Formato de ID do robô inválido no token."})
+		return
+	}
 
-	txErr := ctrl.DB.Transaction(func(tx *gorm.DB) error {
-		var robo models.Robot
-		if err := tx.Where("id = ?", roboIDStr).Preload("User").Preload("Plans").First(&robo).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return &appError{status: http.StatusUnauthorized, message: "Robô não encontrado."}
-			}
-			return err
-		}
+	state, err := ctrl.loadConversaState(roboID)
+	if err != nil {
+		respondConversaError(c, err)
+		return
+	}
 
-		if robo.User == nil {
-			return &appError{status: http.StatusForbidden, message: "Robô não está vinculado a um usuário."}
-		}
+	// O RoboAuthMiddleware só expõe a assinatura quando ela está ativa
+	// (incluindo inadimplentes dentro do período de graça)
+	_, hasActiveSubscription := c.Get("subscription")
 
-		if robo.PlanValidUntil == nil || time.Now().After(*robo.PlanValidUntil) {
-			return &appError{status: http.StatusPaymentRequired, message: "Plano do robô está inativo ou expirado."}
-		}
+	// 1. Valida e reserva a mensagem da cota
+	robo, err := ctrl.reserveConversa(roboIDStr, hasActiveSubscription, state)
+	if err != nil {
+		respondConversaError(c, err)
+		return
+	}
 
-		const limiteMensagensPlanoBasico = 200
-		if robo.User.MessagesUsed >= limiteMensagensPlanoBasico {
-			return &appError{status: http.StatusTooManyRequests, message: "Limite de mensagens do plano atingido."}
-		}
+	// 2. Chama o modelo fora de qualquer transação: uma chamada lenta não
+	// pode segurar o lock de escrita do SQLite
+	result, err := ctrl.IAService.Generate(c.Request.Context(), state.iaRequest(req.Texto))
+	if err != nil {
+		ctrl.releaseQuota(state)
+		respondConversaError(c, &appError{status: http.StatusInternalServerError, message: "Erro ao comunicar com o serviço de IA: " + err.Error()})
+		return
+	}
 
-		respostaIA, emocaoIA, err = ctrl.IAService.Generate(req.Texto)
-		if err != nil {
-			return &appError{status: http.StatusInternalServerError, message: "Erro ao comunicar com o serviço de IA: " + err.Error()}
-		}
+	// 3. Grava log e consumo numa transação curta
+	emocao, err := ctrl.commitConversa(robo, state, req.Texto, result)
+	if err != nil {
+		respondConversaError(c, err)
+		return
+	}
 
-		logConversa := models.ConversaLog{
-			RoboID:   robo.ID,
-			Pergunta: req.Texto,
-			Resposta: respostaIA,
-			Emocao:   emocaoIA,
-		}
-		if err := tx.Create(&logConversa).Error; err != nil {
+	c.JSON(http.StatusOK, conversaResponse(result.Resposta, emocao))
+}
+
+// ConversaStream é a variante de Conversa que envia a resposta via Server-Sent Events:
+// eventos "chunk" com pedaços da resposta e, ao final, "done" com resposta e emoção.
+// A mensagem é reservada na cota antes da geração e devolvida se o streaming falhar;
+// o log só é gravado se ele terminar com sucesso.
+func (ctrl *ConversaController) ConversaStream(c *gin.Context) {
+	roboIDStr := c.GetString("robo_id")
+	roboID, err := uuid.Parse(roboIDStr)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Formato de ID do robô inválido no token."})
+		return
+	}
+
+	var req dtos.ConversaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido: " + err.Error()})
+		return
+	}
+
+	state, err := ctrl.loadConversaState(roboID)
+	if err != nil {
+		respondConversaError(c, err)
+		return
+	}
+
+	_, hasActiveSubscription := c.Get("subscription")
+	robo, err := ctrl.reserveConversa(roboIDStr, hasActiveSubscription, state)
+	if err != nil {
+		respondConversaError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	result, err := ctrl.IAService.GenerateStream(c.Request.Context(), state.iaRequest(req.Texto), func(delta string) error {
+		// Robô desconectou: interrompe a geração sem gravar nada
+		if err := c.Request.Context().Err(); err != nil {
 			return err
 		}
+		c.SSEvent("chunk", gin.H{"resposta": delta})
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		ctrl.releaseQuota(state)
+		c.SSEvent("error", gin.H{"error": "Erro ao comunicar com o serviço de IA: " + err.Error()})
+		return
+	}
+	if result.Fallback {
+		// Nenhum pedaço foi enviado; a resposta de fallback sai inteira num só chunk
+		c.SSEvent("chunk", gin.H{"resposta": result.Resposta})
+	}
 
-		now := time.Now()
-		if err := tx.Model(&models.Robot{}).Where("id = ?", robo.ID).Update("last_ping", &now).Error; err != nil {
-			return err
+	emocao, err := ctrl.commitConversa(robo, state, req.Texto, result)
+	if err != nil {
+		c.SSEvent("error", gin.H{"error": "Erro interno do servidor: " + err.Error()})
+		return
+	}
+
+	c.SSEvent("done", conversaResponse(result.Resposta, emocao))
+}
+
+// conversaState reúne o que é carregado antes de chamar a IA
+type conversaState struct {
+	quota       *services.MessageQuota
+	reservation *models.QuotaReservation
+	session     *models.ConversaSession
+	history     []services.LLMMessage
+	persona     services.Persona
+}
+
+func (s *conversaState) iaRequest(texto string) services.IARequest {
+	return services.IARequest{
+		SystemPrompt: services.BuildSystemPrompt(s.persona),
+		History:      s.history,
+		Texto:        texto,
+	}
+}
+
+// loadConversaState resolve a cota do período, o contexto da sessão e a persona (fora de transação)
+func (ctrl *ConversaController) loadConversaState(roboID uuid.UUID) (*conversaState, error) {
+	quota, err := ctrl.UsageService.CurrentQuota(roboID)
+	if err != nil {
+		return nil, &appError{status: http.StatusInternalServerError, message: "Erro ao verificar a cota de mensagens: " + err.Error()}
+	}
+
+	session, err := ctrl.ConversaService.CurrentSession(roboID)
+	if err != nil {
+		return nil, &appError{status: http.StatusInternalServerError, message: "Erro ao carregar a sessão de conversa: " + err.Error()}
+	}
+
+	history, err := ctrl.ConversaService.History(session)
+	if err != nil {
+		return nil, &appError{status: http.StatusInternalServerError, message: "Erro ao carregar o histórico da conversa: " + err.Error()}
+	}
+
+	persona, err := ctrl.PersonaService.Resolve(roboID)
+	if err != nil {
+		return nil, &appError{status: http.StatusInternalServerError, message: "Erro ao carregar a persona do robô: " + err.Error()}
+	}
+
+	return &conversaState{quota: quota, session: session, history: history, persona: persona}, nil
+}
+
+// checkRobo valida dono, plano e cota do robô antes de gastar uma chamada de IA
+func checkRobo(db *gorm.DB, roboIDStr string, hasActiveSubscription bool, quota *services.MessageQuota) (*models.Robot, error) {
+	var robo models.Robot
+	if err := db.Where("id = ?", roboIDStr).Preload("User").Preload("Plans").First(&robo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &appError{status: http.StatusUnauthorized, message: "Robô não encontrado."}
 		}
-		if err := tx.Model(&models.User{}).Where("id = ?", robo.User.ID).Update("messages_used", gorm.Expr("messages_used + 1")).Error; err != nil {
-			return err
+		return nil, err
+	}
+
+	if robo.User == nil {
+		return nil, &appError{status: http.StatusForbidden, message: "Robô não está vinculado a um usuário."}
+	}
+
+	if !hasActiveSubscription && (robo.PlanValidUntil == nil || time.Now().After(*robo.PlanValidUntil)) {
+		return nil, &appError{status: http.StatusPaymentRequired, message: "Plano do robô está inativo ou expirado."}
+	}
+
+	if quota.Exceeded() {
+		return nil, &appError{status: http.StatusTooManyRequests, message: "Limite de mensagens do plano atingido."}
+	}
+
+	return &robo, nil
+}
+
+// reserveConversa valida o robô e reserva uma mensagem da cota antes da chamada de IA
+func (ctrl *ConversaController) reserveConversa(roboIDStr string, hasActiveSubscription bool, state *conversaState) (*models.Robot, error) {
+	robo, err := checkRobo(ctrl.DB, roboIDStr, hasActiveSubscription, state.quota)
+	if err != nil {
+		return nil, err
+	}
+
+	// A verificação em checkRobo é só um atalho; quem garante o limite sob
+	// requisições simultâneas é a reserva condicional
+	reservation, err := ctrl.UsageService.Reserve(state.quota)
+	if err != nil {
+		if errors.Is(err, services.ErrQuotaExceeded) {
+			return nil, &appError{status: http.StatusTooManyRequests, message: "Limite de mensagens do plano atingido."}
 		}
+		return nil, &appError{status: http.StatusInternalServerError, message: "Erro ao reservar a cota de mensagens: " + err.Error()}
+	}
+	state.reservation = reservation
 
-		return nil
-	})
+	return robo, nil
+}
+
+// releaseQuota devolve a mensagem reservada quando a conversa não chega a ser gravada
+func (ctrl *ConversaController) releaseQuota(state *conversaState) {
+	if err := ctrl.UsageService.Release(state.reservation); err != nil {
+		log.Printf("Erro ao liberar a reserva de cota %s: %v", state.reservation.ID, err)
+	}
+}
+
+// commitConversa normaliza a emoção, calcula o custo e grava a conversa numa
+// transação curta. Em caso de erro a reserva de cota é liberada.
+func (ctrl *ConversaController) commitConversa(robo *models.Robot, state *conversaState, pergunta string, result *services.IAResult) (*services.ResolvedEmotion, error) {
+	emocao, err := ctrl.EmotionService.Resolve(result.Emocao, state.persona.AllowedEmotions, robo.HardwareModel)
+	if err != nil {
+		ctrl.releaseQuota(state)
+		return nil, err
+	}
 
-	if txErr != nil {
-		if ae, ok := txErr.(*appError); ok {
-			c.JSON(ae.status, gin.H{"error": ae.message})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno do servidor: " + txErr.Error()})
+	// A resposta também vai para o servidor de display do robô, via outbox
+	display, err := ctrl.OutboxService.NewDisplayMessage(robo, result.Resposta, emocao.ID)
+	if err != nil {
+		ctrl.releaseQuota(state)
+		return nil, err
+	}
+
+	// A resposta de fallback não consome cota nem entra no histórico da sessão
+	if result.Fallback {
+		ctrl.releaseQuota(state)
+		if err := ctrl.OutboxService.Enqueue(display); err != nil {
+			log.Printf("Erro ao enfileirar a resposta de fallback do robô %s: %v", robo.ID, err)
 		}
-		return
+		return emocao, nil
 	}
 
-	// Enviar mensagem para o servidor Python (assíncrono)
-	go ctrl.sendToPythonServer(respostaIA, emocaoIA)
+	custo, err := ctrl.CostService.Cost(result.Model, result.PromptTokens, result.CompletionTokens)
+	if err != nil {
+		ctrl.releaseQuota(state)
+		return nil, err
+	}
 
-	c.JSON(http.StatusOK, dtos.ConversaResponse{
-		Resposta: respostaIA,
-		Emocao:   emocaoIA,
-	})
+	if err := ctrl.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveConversa(tx, robo, state, pergunta, result, emocao.ID, custo); err != nil {
+			return err
+		}
+		return tx.Create(display).Error
+	}); err != nil {
+		ctrl.releaseQuota(state)
+		return nil, err
+	}
+	ctrl.OutboxService.Notify()
+
+	return emocao, nil
 }
 
-type appError struct {
-	status  int
-	message string
+// saveConversa grava o log da troca, com tokens e custo da chamada, e confirma a reserva de cota
+func saveConversa(tx *gorm.DB, robo *models.Robot, state *conversaState, pergunta string, result *services.IAResult, emocao string, custo float64) error {
+	logConversa := models.ConversaLog{
+		RoboID:           robo.ID,
+		SessionID:        &state.session.ID,
+		Pergunta:         pergunta,
+		Resposta:         result.Resposta,
+		Emocao:           emocao,
+		Custo:            custo,
+		ModeloIA:         result.Model,
+		PromptTokens:     result.PromptTokens,
+		CompletionTokens: result.CompletionTokens,
+		PlanType:         state.quota.Usage.PlanType,
+	}
+	if err := tx.Create(&logConversa).Error; err != nil {
+		return err
+	}
+
+	// Confirma a reserva; se o sweeper já a liberou por vencimento, a mensagem não conta mais
+	commit := tx.Model(&models.QuotaReservation{}).
+		Where("id = ? AND status = ?", state.reservation.ID, models.ReservationPending).
+		Update("status", models.ReservationCommitted)
+	if commit.Error != nil {
+		return commit.Error
+	}
+	if commit.RowsAffected == 0 {
+		return errQuotaReservationExpired
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.ConversaSession{}).Where("id = ?", state.session.ID).Update("last_message_at", &now).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Robot{}).Where("id = ?", robo.ID).Update("last_ping", &now).Error; err != nil {
+		return err
+	}
+	// Total histórico do usuário (não é usado para limitar)
+	return tx.Model(&models.User{}).Where("id = ?", robo.User.ID).Update("messages_used", gorm.Expr("messages_used + 1")).Error
 }
 
-func (e *appError) Error() string {
-	return e.message
+// conversaResponse monta a resposta ao robô com a animação da emoção normalizada
+func conversaResponse(resposta string, emocao *services.ResolvedEmotion) dtos.ConversaResponse {
+	response := dtos.ConversaResponse{
+		Resposta: resposta,
+		Emocao:   emocao.ID,
+	}
+	if emocao.Animation != nil {
+		response.Animacao = &dtos.Animacao{
+			Codigo: emocao.Animation.AnimationCode,
+			LED:    emocao.Animation.LEDCode,
+		}
+	}
+	return response
 }
 
-// PythonMessage representa a estrutura da mensagem enviada para o servidor Python
-type PythonMessage struct {
-	Message string `json:"message"`
-	Emotion string `json:"emotion"`
+func respondConversaError(c *gin.Context, err error) {
+	if ae, ok := err.(*appError); ok {
+		c.JSON(ae.status, gin.H{"error": ae.message})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno do servidor: " + err.Error()})
 }
 
-// sendToPythonServer envia a resposta da IA para o servidor Python
-func (ctrl *ConversaController) sendToPythonServer(resposta, emocao string) {
-	// URL do servidor Python
-	pythonServerURL := "http://localhost:3000/process_message"
-	
-	// Criar payload
-	payload := PythonMessage{
-		Message: resposta,
-		Emotion: emocao,
+// Usage retorna o consumo de mensagens do robô autenticado no período atual
+func (ctrl *ConversaController) Usage(c *gin.Context) {
+	roboID, err := uuid.Parse(c.GetString("robo_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Formato de ID do robô inválido no token."})
+		return
 	}
-	
-	jsonData, err := json.Marshal(payload)
+
+	quota, err := ctrl.UsageService.CurrentQuota(roboID)
 	if err != nil {
-		// Log do erro, mas não interrompe o fluxo principal
-		println("Erro ao serializar mensagem para Python:", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar a cota de mensagens: " + err.Error()})
 		return
 	}
-	
-	// Fazer requisição HTTP
-	client := &http.Client{
-		Timeout: time.Second * 5, // Timeout de 5 segundos
+
+	c.JSON(http.StatusOK, dtos.ConvertToMessageUsageResponseDTO(*quota.Usage, quota.Limit))
+}
+
+// NewSession encerra a sessão atual do robô autenticado; a próxima mensagem começa sem contexto
+func (ctrl *ConversaController) NewSession(c *gin.Context) {
+	roboID, err := uuid.Parse(c.GetString("robo_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Formato de ID do robô inválido no token."})
+		return
 	}
-	
-	resp, err := client.Post(pythonServerURL, "application/json", bytes.NewBuffer(jsonData))
+
+	session, err := ctrl.ConversaService.StartNewSession(roboID)
 	if err != nil {
-		// Log do erro, mas não interrompe o fluxo principal
-		println("Erro ao enviar mensagem para servidor Python:", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao iniciar nova sessão: " + err.Error()})
 		return
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		println("Servidor Python retornou status:", resp.StatusCode)
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// NewSessionForRobot permite ao dono reiniciar a conversa de um robô seu
+func (ctrl *ConversaController) NewSessionForRobot(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	session, err := ctrl.ConversaService.StartNewSessionForOwner(c.Param("id"), userID.(string))
+	if err != nil {
+		if errors.Is(err, services.ErrRobotNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	
-	println("✅ Mensagem enviada para o servidor Python com sucesso!")
+
+	c.JSON(http.StatusCreated, session)
+}
+
+var errQuotaReservationExpired = &appError{status: http.StatusConflict, message: "A reserva de cota expirou antes da resposta da IA; tente novamente."}
+
+type appError struct {
+	status  int
+	message string
+}
+
+func (e *appError) Error() string {
+	return e.message
 }
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+type OutboxController interface {
+	FindAll(c *gin.Context)
+	FindByID(c *gin.Context)
+	Retry(c *gin.Context)
+}
+
+type outboxController struct {
+	service services.OutboxService
+}
+
+func NewOutboxController(service services.OutboxService) OutboxController {
+	return &outboxController{service: service}
+}
+
+// FindAll lista as entregas mais recentes (?status=pending|sending|delivered|dead&robot_id=)
+func (ctrl *outboxController) FindAll(c *gin.Context) {
+	messages, err := ctrl.service.FindAll(models.OutboxStatus(c.Query("status")), c.Query("robot_id"))
+	if err != nil {
+		respondOutboxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+func (ctrl *outboxController) FindByID(c *gin.Context) {
+	message, err := ctrl.service.FindByID(c.Param("id"))
+	if err != nil {
+		respondOutboxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// Retry reenvia uma mensagem que falhou, zerando as tentativas
+func (ctrl *outboxController) Retry(c *gin.Context) {
+	message, err := ctrl.service.Retry(c.Param("id"))
+	if err != nil {
+		respondOutboxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, message)
+}
+
+func respondOutboxError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidOutboxFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOutboxMessageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOutboxMessageNotRetryable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
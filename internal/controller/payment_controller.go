@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -52,17 +53,6 @@ func (ctrl *paymentController) CreateRobotPayment(c *gin.Context) {
 		return
 	}
 
-	validPlans := map[string]bool{
-		"basic":      true,
-		"premium":    true,
-		"enterprise": true,
-	}
-
-	if !validPlans[req.PlanType] {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid plan type. Valid options: basic, premium, enterprise"})
-		return
-	}
-
 	session, err := ctrl.stripeService.CreateCheckoutSessionForRobot(
 		userID.(string),
 		req.RobotName,
@@ -70,6 +60,10 @@ func (ctrl *paymentController) CreateRobotPayment(c *gin.Context) {
 		user.Email,
 	)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidPlanType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid plan type. See GET /api/plans for available plans"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create payment session: " + err.Error()})
 		return
 	}
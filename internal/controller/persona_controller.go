@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+type PersonaController interface {
+	Find(c *gin.Context)
+	Update(c *gin.Context)
+	Reset(c *gin.Context)
+}
+
+type personaController struct {
+	service services.PersonaService
+}
+
+func NewPersonaController(service services.PersonaService) PersonaController {
+	return &personaController{service: service}
+}
+
+func (ctrl *personaController) Find(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	robotID, persona, err := ctrl.service.FindForOwner(c.Param("id"), userID.(string))
+	if err != nil {
+		respondPersonaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPersonaResponse(robotID, persona))
+}
+
+func (ctrl *personaController) Update(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var input dtos.UpdatePersonaInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	robotID, persona, err := ctrl.service.UpdateForOwner(c.Param("id"), userID.(string), input)
+	if err != nil {
+		respondPersonaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPersonaResponse(robotID, persona))
+}
+
+// Reset volta o robô para a persona padrão
+func (ctrl *personaController) Reset(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	if err := ctrl.service.ResetForOwner(c.Param("id"), userID.(string)); err != nil {
+		respondPersonaError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondPersonaError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRobotNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownPersonaVersion), errors.Is(err, services.ErrInvalidEmotion):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func toPersonaResponse(robotID uuid.UUID, persona services.Persona) dtos.PersonaResponseDTO {
+	return dtos.PersonaResponseDTO{
+		RobotID:         robotID,
+		Customized:      persona.Customized,
+		DefaultVersion:  persona.DefaultVersion,
+		Persona:         persona.Text,
+		Language:        persona.Language,
+		Formality:       persona.Formality,
+		AllowedEmotions: persona.AllowedEmotions,
+		ResponseLength:  persona.ResponseLength,
+	}
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+type PlanCatalogController interface {
+	FindActive(c *gin.Context)
+	FindAll(c *gin.Context)
+	Create(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type planCatalogController struct {
+	service services.PlanCatalogService
+}
+
+func NewPlanCatalogController(service services.PlanCatalogService) PlanCatalogController {
+	return &planCatalogController{service: service}
+}
+
+// FindActive lista os planos disponíveis para venda (público)
+func (ctrl *planCatalogController) FindActive(c *gin.Context) {
+	plans, err := ctrl.service.FindAll(true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// FindAll lista todos os planos, incluindo os desativados (admin)
+func (ctrl *planCatalogController) FindAll(c *gin.Context) {
+	plans, err := ctrl.service.FindAll(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+func (ctrl *planCatalogController) Create(c *gin.Context) {
+	var input dtos.CreatePlanCatalogInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	plan, err := ctrl.service.Create(input)
+	if err != nil {
+		if errors.Is(err, services.ErrPlanAlreadyExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+func (ctrl *planCatalogController) Update(c *gin.Context) {
+	var input dtos.UpdatePlanCatalogInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	plan, err := ctrl.service.Update(c.Param("code"), input)
+	if err != nil {
+		if errors.Is(err, services.ErrPlanNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (ctrl *planCatalogController) Delete(c *gin.Context) {
+	if err := ctrl.service.Deactivate(c.Param("code")); err != nil {
+		if errors.Is(err, services.ErrPlanNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
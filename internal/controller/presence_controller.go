@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+type PresenceController interface {
+	Heartbeat(c *gin.Context)
+	History(c *gin.Context)
+}
+
+type presenceController struct {
+	service services.PresenceService
+}
+
+func NewPresenceController(service services.PresenceService) PresenceController {
+	return &presenceController{service: service}
+}
+
+// Heartbeat recebe o sinal de vida do robô autenticado, com versão de firmware e IP
+func (ctrl *presenceController) Heartbeat(c *gin.Context) {
+	roboID, err := uuid.Parse(c.GetString("robo_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Formato de ID do robô inválido no token."})
+		return
+	}
+
+	var input dtos.HeartbeatInputDTO
+	// Corpo vazio é aceito: o heartbeat mínimo só atualiza o LastPing
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+			return
+		}
+	}
+
+	if err := ctrl.service.Heartbeat(roboID, input, c.ClientIP()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// History lista as últimas transições online/offline de um robô do usuário
+func (ctrl *presenceController) History(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	events, err := ctrl.service.HistoryForOwner(c.Param("id"), userID.(string))
+	if err != nil {
+		if errors.Is(err, services.ErrRobotNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+// ReportController expõe a tabela de preços dos modelos de IA e os relatórios de gasto
+type ReportController interface {
+	FindPrices(c *gin.Context)
+	SetPrice(c *gin.Context)
+	DeletePrice(c *gin.Context)
+	AIUsage(c *gin.Context)
+	PlanRevenue(c *gin.Context)
+}
+
+type reportController struct {
+	costService   services.CostService
+	reportService services.ReportService
+}
+
+func NewReportController(costService services.CostService, reportService services.ReportService) ReportController {
+	return &reportController{costService: costService, reportService: reportService}
+}
+
+func (ctrl *reportController) FindPrices(c *gin.Context) {
+	prices, err := ctrl.costService.FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prices)
+}
+
+// SetPrice cria ou substitui o preço de um modelo; vale para as próximas conversas
+func (ctrl *reportController) SetPrice(c *gin.Context) {
+	var input dtos.ModelPriceInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	price, err := ctrl.costService.SetPrice(c.Param("model"), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, price)
+}
+
+func (ctrl *reportController) DeletePrice(c *gin.Context) {
+	if err := ctrl.costService.DeletePrice(c.Param("model")); err != nil {
+		if errors.Is(err, services.ErrModelPriceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AIUsage agrega tokens e custo por robô, usuário ou mês (?group_by=robot|user|month&from=YYYY-MM&to=YYYY-MM)
+func (ctrl *reportController) AIUsage(c *gin.Context) {
+	rows, err := ctrl.reportService.AIUsage(c.Query("group_by"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rows)
+}
+
+// PlanRevenue mostra o gasto com IA ao lado da receita do Stripe de cada plano
+func (ctrl *reportController) PlanRevenue(c *gin.Context) {
+	rows, err := ctrl.reportService.PlanRevenue(c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rows)
+}
+
+func respondReportError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidReportGroup) || errors.Is(err, services.ErrInvalidReportPeriod) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
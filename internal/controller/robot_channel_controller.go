@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	robotChannelMaxMessage   = 64 << 10
+	robotChannelWriteTimeout = 10 * time.Second
+)
+
+// RobotChannelController expõe o canal WebSocket do robô e o envio de falas pelo dono
+type RobotChannelController interface {
+	Connect(c *gin.Context)
+	Speak(c *gin.Context)
+}
+
+type robotChannelController struct {
+	hub            services.RobotHub
+	authorizer     services.RobotAuthorizer
+	commandService services.RobotCommandService
+	robotService   services.RobotService
+	config         services.RobotChannelConfig
+}
+
+func NewRobotChannelController(hub services.RobotHub, authorizer services.RobotAuthorizer, commandService services.RobotCommandService, robotService services.RobotService, config services.RobotChannelConfig) RobotChannelController {
+	return &robotChannelController{
+		hub:            hub,
+		authorizer:     authorizer,
+		commandService: commandService,
+		robotService:   robotService,
+		config:         config,
+	}
+}
+
+// Connect abre o canal em tempo real do robô autenticado pelo RoboAuthMiddleware.
+// O token do handshake é revalidado a cada RecheckInterval com as mesmas regras
+// do middleware; se deixar de valer, o robô recebe uma mensagem "error" e a
+// conexão é encerrada.
+func (ctrl *robotChannelController) Connect(c *gin.Context) {
+	roboID, err := uuid.Parse(c.GetString("robo_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Formato de ID do robô inválido no token."})
+		return
+	}
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+	// Robôs não são navegadores: o handshake não checa Origin
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		ctrl.serve(ws, roboID, token)
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (ctrl *robotChannelController) serve(ws *websocket.Conn, robotID uuid.UUID, token string) {
+	defer ws.Close()
+	ws.MaxPayloadBytes = robotChannelMaxMessage
+
+	conn := ctrl.hub.Register(robotID)
+	defer ctrl.hub.Unregister(conn)
+	log.Printf("Robô %s conectado ao canal em tempo real\n", robotID)
+	defer log.Printf("Robô %s desconectado do canal em tempo real\n", robotID)
+
+	// Leitura: responde aos pings do robô. Sem nenhuma mensagem em dois
+	// intervalos de ping (nem o pong), a conexão é considerada morta.
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			ws.SetReadDeadline(time.Now().Add(2 * ctrl.config.PingInterval))
+			var message dtos.RobotChannelMessage
+			if err := websocket.JSON.Receive(ws, &message); err != nil {
+				return
+			}
+			if message.Type == dtos.ChannelPing {
+				conn.Push(dtos.NewRobotChannelMessage(dtos.ChannelPong, nil))
+			}
+		}
+	}()
+
+	if _, err := ctrl.commandService.PushPending(robotID); err != nil {
+		log.Printf("Erro ao enviar comandos pendentes ao robô %s: %v\n", robotID, err)
+	}
+
+	ping := time.NewTicker(ctrl.config.PingInterval)
+	defer ping.Stop()
+	recheck := time.NewTicker(ctrl.config.RecheckInterval)
+	defer recheck.Stop()
+
+	for {
+		select {
+		case <-readerDone:
+			return
+		case <-conn.Done():
+			return
+		case message := <-conn.Messages():
+			if err := writeChannelMessage(ws, message); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := writeChannelMessage(ws, dtos.NewRobotChannelMessage(dtos.ChannelPing, nil)); err != nil {
+				return
+			}
+		case <-recheck.C:
+			if _, err := ctrl.authorizer.Authorize(token); err != nil {
+				log.Printf("Canal em tempo real do robô %s encerrado: %v\n", robotID, err)
+				writeChannelMessage(ws, dtos.NewRobotChannelMessage(dtos.ChannelError, dtos.ChannelErrorData{Error: err.Error()}))
+				return
+			}
+		}
+	}
+}
+
+func writeChannelMessage(ws *websocket.Conn, message dtos.RobotChannelMessage) error {
+	ws.SetWriteDeadline(time.Now().Add(robotChannelWriteTimeout))
+	return websocket.JSON.Send(ws, message)
+}
+
+// Speak envia uma fala proativa a um robô do usuário que esteja conectado
+func (ctrl *robotChannelController) Speak(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var input dtos.PushSpeechInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	sent, err := ctrl.robotService.Speak(c.Param("id"), userID.(string), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRobotNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrRobotNotConnected):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"connections": sent})
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+// Espera padrão do long-polling quando o robô não informa ?wait=
+const defaultCommandWaitSeconds = 30
+
+type RobotCommandController interface {
+	Create(c *gin.Context)
+	FindAll(c *gin.Context)
+	FindByID(c *gin.Context)
+	Poll(c *gin.Context)
+	Ack(c *gin.Context)
+}
+
+type robotCommandController struct {
+	service services.RobotCommandService
+}
+
+func NewRobotCommandController(service services.RobotCommandService) RobotCommandController {
+	return &robotCommandController{service: service}
+}
+
+// Create enfileira um comando para um robô do usuário
+func (ctrl *robotCommandController) Create(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var input dtos.CreateRobotCommandInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	command, err := ctrl.service.Create(c.Param("id"), userID.(string), input)
+	if err != nil {
+		respondCommandError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, command)
+}
+
+// FindAll lista os últimos comandos de um robô do usuário (?status=queued|delivered|acked|failed)
+func (ctrl *robotCommandController) FindAll(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	commands, err := ctrl.service.FindAllForOwner(c.Param("id"), userID.(string), models.CommandStatus(c.Query("status")))
+	if err != nil {
+		respondCommandError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, commands)
+}
+
+func (ctrl *robotCommandController) FindByID(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	command, err := ctrl.service.FindForOwner(c.Param("id"), c.Param("command_id"), userID.(string))
+	if err != nil {
+		respondCommandError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, command)
+}
+
+// Poll entrega ao robô autenticado os comandos pendentes. Sem comandos, segura a
+// requisição por até ?wait= segundos (padrão 30, máximo 60; 0 responde na hora).
+func (ctrl *robotCommandController) Poll(c *gin.Context) {
+	roboID, err := uuid.Parse(c.GetString("robo_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Formato de ID do robô inválido no token."})
+		return
+	}
+
+	wait := defaultCommandWaitSeconds
+	if raw := c.Query("wait"); raw != "" {
+		if wait, err = strconv.Atoi(raw); err != nil || wait < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "wait must be a non-negative number of seconds"})
+			return
+		}
+	}
+
+	commands, err := ctrl.service.Poll(c.Request.Context(), roboID, time.Duration(wait)*time.Second)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, commands)
+}
+
+// Ack recebe do robô o resultado de um comando entregue
+func (ctrl *robotCommandController) Ack(c *gin.Context) {
+	roboID, err := uuid.Parse(c.GetString("robo_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Formato de ID do robô inválido no token."})
+		return
+	}
+
+	var input dtos.AckRobotCommandInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	command, err := ctrl.service.Ack(roboID, c.Param("id"), input)
+	if err != nil {
+		respondCommandError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, command)
+}
+
+func respondCommandError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCommandType), errors.Is(err, services.ErrInvalidCommandPayload):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRobotNotFound), errors.Is(err, services.ErrCommandNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCommandNotAckable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -21,6 +22,7 @@ type RobotController interface {
 	FindByName(c *gin.Context)
 	GenerateToken(c *gin.Context)
 	FindAll(c *gin.Context)
+	UpdateSettings(c *gin.Context)
 }
 
 func (ctrl *robotController) FindAll(c *gin.Context) {
@@ -88,7 +90,7 @@ func (ctrl *robotController) Create(c *gin.Context) {
 }
 
 func (ctrl *robotController) FindByName(c *gin.Context) {
-	name := c.Param("name")
+	name := c.Param("id") // GET /robots/{name}: o wildcard se chama :id por causa das rotas /robots/:id/...
 	robot, err := ctrl.services.FindByName(name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -103,3 +105,32 @@ func (ctrl *robotController) FindByName(c *gin.Context) {
 	c.JSON(http.StatusOK, robot)
 }
 
+// UpdateSettings altera servidor de display e modelo de hardware de um robô do usuário
+func (ctrl *robotController) UpdateSettings(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var input dtos.UpdateRobotSettingsInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	robot, err := ctrl.services.UpdateSettings(c.Param("id"), userID.(string), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRobotNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidDisplayURL):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.ConvertToRobotResponseDTO(*robot))
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+// RobotTokenController lista e revoga os tokens de um robô e permite ao robô renovar o seu
+type RobotTokenController interface {
+	FindAll(c *gin.Context)
+	Revoke(c *gin.Context)
+	Rotate(c *gin.Context)
+}
+
+type robotTokenController struct {
+	service services.RobotService
+}
+
+func NewRobotTokenController(service services.RobotService) RobotTokenController {
+	return &robotTokenController{service: service}
+}
+
+// FindAll lista os tokens emitidos para um robô do usuário, do mais recente ao mais antigo
+func (ctrl *robotTokenController) FindAll(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	tokens, err := ctrl.service.FindTokens(c.Param("id"), userID.(string))
+	if err != nil {
+		if errors.Is(err, services.ErrRobotNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Revoke invalida um token na hora; o robô que o usa passa a receber 401
+func (ctrl *robotTokenController) Revoke(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	token, err := ctrl.service.RevokeToken(c.Param("id"), c.Param("token_id"), userID.(string))
+	if err != nil {
+		if errors.Is(err, services.ErrRobotNotFound) || errors.Is(err, services.ErrRobotTokenNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// Rotate troca o token do robô autenticado por um novo; o atual deixa de valer
+func (ctrl *robotTokenController) Rotate(c *gin.Context) {
+	roboID, err := uuid.Parse(c.GetString("robo_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Formato de ID do robô inválido no token."})
+		return
+	}
+
+	token, err := ctrl.service.IssueToken(roboID, models.TokenFromRotation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
@@ -1,19 +1,22 @@
 package controller
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
 	"github.com/peruccii/roadmap-go-backend/internal/services"
-	"github.com/stripe/stripe-go/v82"
 )
 
 type StripeController interface {
 	StripeWebhookController(c *gin.Context)
+	ListEvents(c *gin.Context)
+	FindEvent(c *gin.Context)
+	ReplayEvent(c *gin.Context)
 }
 
 type stripeController struct {
@@ -34,18 +37,71 @@ func (ctrl *stripeController) StripeWebhookController(c *gin.Context) {
 		return
 	}
 
-	event := stripe.Event{}
-
-	if err := json.Unmarshal(payload, &event); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to parse webhook body json: %v\n", err.Error())
-		c.AbortWithStatus(http.StatusBadRequest)
+	event, err := ctrl.service.ConstructWebhookEvent(payload, c.GetHeader("Stripe-Signature"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Webhook rejected: %v\n", err)
+		switch {
+		case errors.Is(err, services.ErrWebhookSignatureInvalid):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+		case errors.Is(err, services.ErrWebhookSignatureMissing):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "missing or malformed Stripe-Signature header"})
+		case errors.Is(err, services.ErrWebhookPayloadInvalid):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		default:
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}
 		return
 	}
 
-	if err := ctrl.service.HandleEvents(event); err != nil {
+	if err := ctrl.service.ProcessWebhookEvent(event, payload); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to process webhook event %s: %v\n", event.ID, err)
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
+
+func (ctrl *stripeController) ListEvents(c *gin.Context) {
+	status := models.StripeEventStatus(c.Query("status"))
+
+	events, err := ctrl.service.ListEvents(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+func (ctrl *stripeController) FindEvent(c *gin.Context) {
+	event, err := ctrl.service.FindEvent(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrStripeEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+func (ctrl *stripeController) ReplayEvent(c *gin.Context) {
+	event, err := ctrl.service.ReplayEvent(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrStripeEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrStripeEventInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "event": event})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "replay failed: " + err.Error(), "event": event})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+type SubscriptionController interface {
+	FindAll(c *gin.Context)
+	Cancel(c *gin.Context)
+	Resume(c *gin.Context)
+	ChangePlan(c *gin.Context)
+	Usage(c *gin.Context)
+}
+
+type subscriptionController struct {
+	subscriptionService services.SubscriptionService
+	stripeService       services.StripeService
+	usageService        services.UsageService
+}
+
+func NewSubscriptionController(subscriptionService services.SubscriptionService, stripeService services.StripeService, usageService services.UsageService) SubscriptionController {
+	return &subscriptionController{
+		subscriptionService: subscriptionService,
+		stripeService:       stripeService,
+		usageService:        usageService,
+	}
+}
+
+func (ctrl *subscriptionController) FindAll(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	subscriptions, err := ctrl.subscriptionService.FindByUserID(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]dtos.SubscriptionResponseDTO, len(subscriptions))
+	for i, subscription := range subscriptions {
+		response[i] = dtos.ConvertToSubscriptionResponseDTO(subscription)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (ctrl *subscriptionController) Cancel(c *gin.Context) {
+	ctrl.update(c, ctrl.stripeService.CancelSubscription)
+}
+
+func (ctrl *subscriptionController) Resume(c *gin.Context) {
+	ctrl.update(c, ctrl.stripeService.ResumeSubscription)
+}
+
+func (ctrl *subscriptionController) ChangePlan(c *gin.Context) {
+	var req dtos.ChangePlanRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	ctrl.update(c, func(subscription *models.Subscription) error {
+		return ctrl.stripeService.ChangeSubscriptionPlan(subscription, req.PlanType)
+	})
+}
+
+// Usage mostra as mensagens usadas e restantes no período atual da assinatura
+func (ctrl *subscriptionController) Usage(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	subscription, err := ctrl.subscriptionService.FindByIDAndUserID(c.Param("id"), userID.(string))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	quota, err := ctrl.usageService.SubscriptionQuota(subscription)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.ConvertToMessageUsageResponseDTO(*quota.Usage, quota.Limit))
+}
+
+// update busca a assinatura do usuário autenticado e aplica a operação
+func (ctrl *subscriptionController) update(c *gin.Context, operation func(subscription *models.Subscription) error) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	subscription, err := ctrl.subscriptionService.FindByIDAndUserID(c.Param("id"), userID.(string))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := operation(subscription); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPlanType), errors.Is(err, services.ErrSamePlan):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrSubscriptionInactive),
+			errors.Is(err, services.ErrSubscriptionNotCancelable),
+			errors.Is(err, services.ErrSubscriptionNotResumable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.ConvertToSubscriptionResponseDTO(*subscription))
+}
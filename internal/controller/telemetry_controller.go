@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/services"
+)
+
+type TelemetryController interface {
+	Ingest(c *gin.Context)
+	Query(c *gin.Context)
+}
+
+type telemetryController struct {
+	service services.TelemetryService
+}
+
+func NewTelemetryController(service services.TelemetryService) TelemetryController {
+	return &telemetryController{service: service}
+}
+
+// Ingest recebe um lote de leituras do robô autenticado
+func (ctrl *telemetryController) Ingest(c *gin.Context) {
+	roboID, err := uuid.Parse(c.GetString("robo_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Formato de ID do robô inválido no token."})
+		return
+	}
+
+	var input dtos.TelemetryBatchInputDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		return
+	}
+
+	result, err := ctrl.service.Ingest(roboID, input)
+	if err != nil {
+		respondTelemetryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result)
+}
+
+// Query agrega uma métrica de um robô do usuário (?metric=battery&from=...&to=...&bucket=60)
+func (ctrl *telemetryController) Query(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	series, err := ctrl.service.QueryForOwner(c.Param("id"), userID.(string), c.Query("metric"), c.Query("from"), c.Query("to"), c.Query("bucket"))
+	if err != nil {
+		respondTelemetryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+func respondTelemetryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTelemetryMetric), errors.Is(err, services.ErrInvalidTelemetryQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRobotNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -7,6 +7,13 @@ type ConversaRequest struct {
 
 // ConversaResponse é o que a API retorna para o robô.
 type ConversaResponse struct {
-	Resposta string `json:"resposta"`
-	Emocao   string `json:"emocao"`
+	Resposta string    `json:"resposta"`
+	Emocao   string    `json:"emocao"`
+	Animacao *Animacao `json:"animacao,omitempty"`
+}
+
+// Animacao é como o modelo do robô deve expressar a emoção.
+type Animacao struct {
+	Codigo string `json:"codigo,omitempty"`
+	LED    string `json:"led,omitempty"`
 }
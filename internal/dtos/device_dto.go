@@ -0,0 +1,67 @@
+package dtos
+
+// ImportDevicesInputDTO é o lote de dispositivos enviado pela fábrica. Sem
+// claim_code ou device_secret, o servidor gera os valores.
+type ImportDevicesInputDTO struct {
+	Devices []ImportDeviceDTO `json:"devices" binding:"required,min=1,max=1000,dive"`
+}
+
+type ImportDeviceDTO struct {
+	Serial        string `json:"serial" binding:"required,max=64"`
+	HardwareModel string `json:"hardware_model" binding:"max=50"`
+	ClaimCode     string `json:"claim_code" binding:"omitempty,min=8,max=64"`
+	DeviceSecret  string `json:"device_secret" binding:"omitempty,min=16,max=128"`
+}
+
+// ImportedDeviceDTO devolve os segredos em texto puro uma única vez, para a
+// impressão da caixa e a gravação do firmware
+type ImportedDeviceDTO struct {
+	ID            string `json:"id"`
+	Serial        string `json:"serial"`
+	HardwareModel string `json:"hardware_model"`
+	ClaimCode     string `json:"claim_code"`
+	DeviceSecret  string `json:"device_secret"`
+}
+
+type ClaimDeviceInputDTO struct {
+	Serial    string `json:"serial" binding:"required,max=64"`
+	ClaimCode string `json:"claim_code" binding:"required,max=64"`
+}
+
+type DeviceBootstrapInputDTO struct {
+	Serial       string `json:"serial" binding:"required,max=64"`
+	DeviceSecret string `json:"device_secret" binding:"required,max=128"`
+}
+
+type DeviceBootstrapResponseDTO struct {
+	RobotID string `json:"robot_id"`
+	Token   string `json:"token"`
+}
+
+// DeviceAuthorizeInputDTO abre um pareamento pelo fluxo de autorização de dispositivo
+type DeviceAuthorizeInputDTO struct {
+	Serial string `json:"serial" binding:"max=64"` // opcional; aparece para o dono na aprovação
+}
+
+// DeviceAuthorizeResponseDTO segue os campos da RFC 8628
+type DeviceAuthorizeResponseDTO struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+type DeviceTokenInputDTO struct {
+	DeviceCode string `json:"device_code" binding:"required,max=128"`
+}
+
+type DeviceTokenResponseDTO struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	RobotID     string `json:"robot_id"`
+}
+
+type ApproveDevicePairingInputDTO struct {
+	UserCode string `json:"user_code" binding:"required,max=16"`
+}
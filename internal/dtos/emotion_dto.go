@@ -0,0 +1,20 @@
+package dtos
+
+type CreateEmotionInputDTO struct {
+	ID        string   `json:"id" binding:"required,max=50"`
+	Name      string   `json:"name" binding:"required,max=100"`
+	Aliases   []string `json:"aliases"`
+	IsDefault bool     `json:"is_default"`
+}
+
+// UpdateEmotionInputDTO permite atualização parcial; campos omitidos não mudam
+type UpdateEmotionInputDTO struct {
+	Name      *string  `json:"name" binding:"omitempty,max=100"`
+	Aliases   []string `json:"aliases"`
+	IsDefault *bool    `json:"is_default"`
+}
+
+type EmotionAnimationInputDTO struct {
+	AnimationCode string `json:"animation_code" binding:"max=100"`
+	LEDCode       string `json:"led_code" binding:"max=100"`
+}
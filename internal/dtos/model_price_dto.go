@@ -0,0 +1,7 @@
+package dtos
+
+// ModelPriceInputDTO define o preço em USD por milhão de tokens de um modelo
+type ModelPriceInputDTO struct {
+	PromptPerMillion     float64 `json:"prompt_per_million" binding:"gte=0"`
+	CompletionPerMillion float64 `json:"completion_per_million" binding:"gte=0"`
+}
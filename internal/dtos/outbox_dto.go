@@ -0,0 +1,7 @@
+package dtos
+
+// DisplayMessage é o corpo enviado ao servidor de display do robô
+type DisplayMessage struct {
+	Message string `json:"message"`
+	Emotion string `json:"emotion"`
+}
@@ -0,0 +1,25 @@
+package dtos
+
+import "github.com/google/uuid"
+
+// UpdatePersonaInputDTO permite atualização parcial; enviar string vazia volta o campo ao padrão
+type UpdatePersonaInputDTO struct {
+	Persona         *string  `json:"persona" binding:"omitempty,max=4000"`
+	Language        *string  `json:"language" binding:"omitempty,max=20"`
+	Formality       *string  `json:"formality" binding:"omitempty,oneof=casual neutral formal"`
+	AllowedEmotions []string `json:"allowed_emotions"`
+	ResponseLength  *string  `json:"response_length" binding:"omitempty,oneof=short medium long"`
+	DefaultVersion  *string  `json:"default_version" binding:"omitempty,max=20"`
+}
+
+// PersonaResponseDTO mostra a persona efetiva do robô (perfil + padrão)
+type PersonaResponseDTO struct {
+	RobotID         uuid.UUID `json:"robot_id"`
+	Customized      bool      `json:"customized"`
+	DefaultVersion  string    `json:"default_version"`
+	Persona         string    `json:"persona"`
+	Language        string    `json:"language"`
+	Formality       string    `json:"formality"`
+	AllowedEmotions []string  `json:"allowed_emotions"`
+	ResponseLength  string    `json:"response_length"`
+}
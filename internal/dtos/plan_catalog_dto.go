@@ -0,0 +1,32 @@
+package dtos
+
+type CreatePlanCatalogInputDTO struct {
+	Code          string          `json:"code" binding:"required,max=50"`
+	Name          string          `json:"name" binding:"required,max=255"`
+	StripePriceID string          `json:"stripe_price_id" binding:"required"`
+	Amount        int64           `json:"amount" binding:"required,gt=0"`
+	Currency      string          `json:"currency" binding:"omitempty,len=3"`
+	Interval      string          `json:"interval" binding:"omitempty,oneof=day week month year"`
+	MessageLimit  int             `json:"message_limit" binding:"gte=0"`
+	Features      map[string]bool `json:"features"`
+	Active        *bool           `json:"active"`
+
+	// 0 usa os padrões do banco (7 dias, 300 segundos)
+	TelemetryRetentionDays     int `json:"telemetry_retention_days" binding:"gte=0"`
+	TelemetryResolutionSeconds int `json:"telemetry_resolution_seconds" binding:"gte=0"`
+}
+
+// UpdatePlanCatalogInputDTO permite atualização parcial; campos omitidos não mudam
+type UpdatePlanCatalogInputDTO struct {
+	Name          *string         `json:"name" binding:"omitempty,max=255"`
+	StripePriceID *string         `json:"stripe_price_id"`
+	Amount        *int64          `json:"amount" binding:"omitempty,gt=0"`
+	Currency      *string         `json:"currency" binding:"omitempty,len=3"`
+	Interval      *string         `json:"interval" binding:"omitempty,oneof=day week month year"`
+	MessageLimit  *int            `json:"message_limit" binding:"omitempty,gte=0"`
+	Features      map[string]bool `json:"features"`
+	Active        *bool           `json:"active"`
+
+	TelemetryRetentionDays     *int `json:"telemetry_retention_days" binding:"omitempty,gt=0"`
+	TelemetryResolutionSeconds *int `json:"telemetry_resolution_seconds" binding:"omitempty,gt=0"`
+}
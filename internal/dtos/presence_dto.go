@@ -0,0 +1,6 @@
+package dtos
+
+type HeartbeatInputDTO struct {
+	FirmwareVersion string `json:"firmware_version" binding:"max=50"`
+	IP              string `json:"ip" binding:"omitempty,ip"` // opcional; sem ele vale o IP de origem da requisição
+}
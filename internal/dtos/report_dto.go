@@ -0,0 +1,22 @@
+package dtos
+
+// AIUsageReportRow agrega mensagens, tokens e custo (USD) de IA por robô, usuário ou mês
+type AIUsageReportRow struct {
+	Key              string  `json:"key"`
+	Messages         int64   `json:"messages"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Cost             float64 `json:"cost_usd"`
+}
+
+// PlanRevenueReportRow compara o gasto com IA (USD) e a receita do Stripe (centavos) de um plano
+type PlanRevenueReportRow struct {
+	PlanType         string  `json:"plan_type"`
+	Messages         int64   `json:"messages"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	AICost           float64 `json:"ai_cost_usd"`
+	Revenue          int64   `json:"revenue"`
+	Currency         string  `json:"currency"`
+	Payments         int64   `json:"payments"`
+}
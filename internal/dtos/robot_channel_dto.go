@@ -0,0 +1,44 @@
+package dtos
+
+import "time"
+
+// Tipos de mensagem do canal em tempo real (WebSocket) do robô
+const (
+	ChannelSpeech  = "speech"  // servidor → robô: fala proativa
+	ChannelCommand = "command" // servidor → robô: comando da fila, confirmado por POST /api/robot/commands/{id}/ack
+	ChannelConfig  = "config"  // servidor → robô: configurações alteradas pelo dono
+	ChannelPing    = "ping"    // nos dois sentidos; quem recebe responde pong
+	ChannelPong    = "pong"
+	ChannelError   = "error" // servidor → robô: motivo do encerramento da conexão
+)
+
+// RobotChannelMessage é o envelope de todas as mensagens do canal
+type RobotChannelMessage struct {
+	Type   string      `json:"type"`
+	Data   interface{} `json:"data,omitempty"`
+	SentAt time.Time   `json:"sent_at"`
+}
+
+func NewRobotChannelMessage(messageType string, data interface{}) RobotChannelMessage {
+	return RobotChannelMessage{Type: messageType, Data: data, SentAt: time.Now().UTC()}
+}
+
+type ChannelSpeechData struct {
+	Text    string `json:"text"`
+	Emotion string `json:"emotion,omitempty"`
+}
+
+type ChannelConfigData struct {
+	DisplayURL    string `json:"display_url"`
+	HardwareModel string `json:"hardware_model"`
+}
+
+type ChannelErrorData struct {
+	Error string `json:"error"`
+}
+
+// PushSpeechInputDTO é a fala enviada pelo dono para o robô conectado
+type PushSpeechInputDTO struct {
+	Text    string `json:"text" binding:"required,max=500"`
+	Emotion string `json:"emotion" binding:"max=50"`
+}
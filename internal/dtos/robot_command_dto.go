@@ -0,0 +1,12 @@
+package dtos
+
+type CreateRobotCommandInputDTO struct {
+	Type       string                 `json:"type" binding:"required,max=30"`
+	Payload    map[string]interface{} `json:"payload"`
+	TTLSeconds int                    `json:"ttl_seconds" binding:"omitempty,min=1,max=86400"` // padrão: COMMAND_DEFAULT_TTL_SECONDS
+}
+
+type AckRobotCommandInputDTO struct {
+	Status string `json:"status" binding:"required,oneof=acked failed"`
+	Result string `json:"result" binding:"max=1000"`
+}
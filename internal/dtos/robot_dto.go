@@ -6,3 +6,9 @@ type CreateRobotRequestInputDTO struct {
 	 Robot  *models.Robot
 	 *models.User
 }
+
+// UpdateRobotSettingsInputDTO permite atualização parcial; campos omitidos não mudam
+type UpdateRobotSettingsInputDTO struct {
+	DisplayURL    *string `json:"display_url" binding:"omitempty,max=255"`
+	HardwareModel *string `json:"hardware_model" binding:"omitempty,max=50"`
+}
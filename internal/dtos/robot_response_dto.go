@@ -20,6 +20,10 @@ type RobotResponseDTO struct {
 	Status         models.RobotStatus `json:"Status"`
 	PlanValidUntil *time.Time        `json:"PlanValidUntil"`
 	LastPing       *time.Time        `json:"ultimo_ping"`
+	HardwareModel  string            `json:"HardwareModel"`
+	DisplayURL     string            `json:"DisplayURL"`
+	Firmware       string            `json:"Firmware"`
+	Online         bool              `json:"Online"`
 	CreatedAt      time.Time         `json:"CreatedAt"`
 	Plans          []PlanResponseDTO `json:"Plans"`
 }
@@ -45,6 +49,10 @@ func ConvertToRobotResponseDTO(robot models.Robot) RobotResponseDTO {
 		Status:         robot.Status,
 		PlanValidUntil: robot.PlanValidUntil,
 		LastPing:       robot.LastPing,
+		HardwareModel:  robot.HardwareModel,
+		DisplayURL:     robot.DisplayURL,
+		Firmware:       robot.Firmware,
+		Online:         robot.Online,
 		CreatedAt:      robot.CreatedAt,
 		Plans:          activePlans,
 	}
@@ -0,0 +1,41 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+)
+
+type ChangePlanRequestDTO struct {
+	PlanType string `json:"plan_type" binding:"required"`
+}
+
+type SubscriptionResponseDTO struct {
+	ID                 uuid.UUID                 `json:"id"`
+	RobotID            uuid.UUID                 `json:"robot_id"`
+	RobotName          string                    `json:"robot_name"`
+	PlanType           models.PlanType           `json:"plan_type"`
+	Status             models.SubscriptionStatus `json:"status"`
+	CurrentPeriodStart time.Time                 `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time                 `json:"current_period_end"`
+	CancelAtPeriodEnd  bool                      `json:"cancel_at_period_end"`
+	CanceledAt         *time.Time                `json:"canceled_at"`
+	GracePeriodEndsAt  *time.Time                `json:"grace_period_ends_at"`
+}
+
+// ConvertToSubscriptionResponseDTO converte um modelo Subscription para DTO de resposta
+func ConvertToSubscriptionResponseDTO(subscription models.Subscription) SubscriptionResponseDTO {
+	return SubscriptionResponseDTO{
+		ID:                 subscription.ID,
+		RobotID:            subscription.RobotID,
+		RobotName:          subscription.Robot.Name,
+		PlanType:           subscription.PlanType,
+		Status:             subscription.Status,
+		CurrentPeriodStart: subscription.CurrentPeriodStart,
+		CurrentPeriodEnd:   subscription.CurrentPeriodEnd,
+		CancelAtPeriodEnd:  subscription.CancelAtPeriodEnd,
+		CanceledAt:         subscription.CanceledAt,
+		GracePeriodEndsAt:  subscription.GracePeriodEndsAt,
+	}
+}
@@ -0,0 +1,36 @@
+package dtos
+
+import "time"
+
+// TelemetryBatchInputDTO é o lote de leituras enviado pelo robô
+type TelemetryBatchInputDTO struct {
+	Points []TelemetryPointInputDTO `json:"points" binding:"required,min=1,max=500,dive"`
+}
+
+type TelemetryPointInputDTO struct {
+	Metric     string     `json:"metric" binding:"required,max=50"`
+	Value      *float64   `json:"value" binding:"required"`
+	RecordedAt *time.Time `json:"recorded_at"` // opcional; sem ele vale o horário de chegada
+}
+
+type TelemetryIngestResponseDTO struct {
+	Accepted int `json:"accepted"`
+	Dropped  int `json:"dropped"` // leituras mais antigas que a retenção do plano
+}
+
+type TelemetryBucketDTO struct {
+	Time  time.Time `json:"time"`
+	Avg   float64   `json:"avg"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Count int64     `json:"count"`
+}
+
+type TelemetrySeriesDTO struct {
+	RobotID       string               `json:"robot_id"`
+	Metric        string               `json:"metric"`
+	From          time.Time            `json:"from"`
+	To            time.Time            `json:"to"`
+	BucketSeconds int                  `json:"bucket_seconds"`
+	Points        []TelemetryBucketDTO `json:"points"`
+}
@@ -0,0 +1,35 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+)
+
+type MessageUsageResponseDTO struct {
+	RobotID     uuid.UUID       `json:"robot_id"`
+	PlanType    models.PlanType `json:"plan_type"`
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"`
+	Limit       int             `json:"limit"` // 0 = ilimitado
+	Used        int             `json:"used"`
+	Remaining   *int            `json:"remaining"` // null quando o plano é ilimitado
+}
+
+// ConvertToMessageUsageResponseDTO converte o contador do período e o limite do plano para DTO de resposta
+func ConvertToMessageUsageResponseDTO(usage models.MessageUsage, limit int) MessageUsageResponseDTO {
+	response := MessageUsageResponseDTO{
+		RobotID:     usage.RobotID,
+		PlanType:    usage.PlanType,
+		PeriodStart: usage.PeriodStart,
+		PeriodEnd:   usage.PeriodEnd,
+		Limit:       limit,
+		Used:        usage.MessagesUsed,
+	}
+	if limit > 0 {
+		remaining := max(limit-usage.MessagesUsed, 0)
+		response.Remaining = &remaining
+	}
+	return response
+}
@@ -8,10 +8,15 @@ import (
 // ConversaLog registra cada interação entre um robô e a IA.
 type ConversaLog struct {
 	gorm.Model
-	RoboID   uuid.UUID    `gorm:"not null"`
+	RoboID    uuid.UUID  `gorm:"not null"`
+	SessionID *uuid.UUID `gorm:"type:uuid;index"`
 	Pergunta string  `gorm:"type:text"`
 	Resposta string  `gorm:"type:text"`
 	Emocao   string  `gorm:"size:50"`
-	Custo    float64 // Opcional: para registrar custo da chamada de IA
+	Custo    float64 // custo da chamada de IA em USD, pela tabela model_prices
+	ModeloIA         string   `gorm:"size:100"` // modelo que gerou a resposta
+	PromptTokens     int
+	CompletionTokens int
+	PlanType         PlanType `gorm:"type:text;index"` // plano do robô no momento da conversa
 	Robot    Robot   `gorm:"foreignKey:RoboID"`
 }
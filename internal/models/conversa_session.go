@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ConversaSession agrupa as mensagens de um mesmo papo com o robô.
+// Só as mensagens da sessão atual entram no contexto enviado à IA.
+type ConversaSession struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	RoboID        uuid.UUID  `json:"robo_id" gorm:"type:uuid;not null;index"`
+	LastMessageAt *time.Time `json:"last_message_at"`
+	EndedAt       *time.Time `json:"ended_at"` // nil = sessão atual do robô
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (s *ConversaSession) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.New()
+	return
+}
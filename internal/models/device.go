@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeviceStatus indica se o dispositivo físico já foi vinculado a um robô
+type DeviceStatus string
+
+const (
+	DeviceUnclaimed DeviceStatus = "unclaimed"
+	DeviceClaimed   DeviceStatus = "claimed"
+)
+
+// Device é um robô físico cadastrado pela fábrica. O código de vinculação vai
+// impresso na caixa e o segredo fica gravado no firmware; os dois são guardados
+// só como hash. O dono vincula o dispositivo ao robô comprado com o código, e o
+// dispositivo troca o segredo por um token do robô.
+type Device struct {
+	ID              uuid.UUID    `json:"id" gorm:"type:uuid;primaryKey"`
+	Serial          string       `json:"serial" gorm:"type:varchar(64);uniqueIndex;not null"`
+	HardwareModel   string       `json:"hardware_model" gorm:"type:varchar(50)"`
+	ClaimCodeHash   string       `json:"-" gorm:"type:varchar(64);not null"`
+	SecretHash      string       `json:"-" gorm:"type:varchar(64);not null"`
+	Status          DeviceStatus `json:"status" gorm:"type:text;default:'unclaimed';index"`
+	RobotID         *uuid.UUID   `json:"robot_id" gorm:"type:uuid;uniqueIndex"`
+	ClaimedBy       *uuid.UUID   `json:"claimed_by" gorm:"type:uuid"`
+	ClaimedAt       *time.Time   `json:"claimed_at"`
+	ClaimAttempts   int          `json:"claim_attempts" gorm:"default:0"` // códigos errados seguidos; bloqueia o vínculo ao chegar no limite
+	LastBootstrapAt *time.Time   `json:"last_bootstrap_at"`
+	CreatedAt       time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (d *Device) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return
+}
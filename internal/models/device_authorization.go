@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeviceAuthorizationStatus acompanha o pareamento pelo fluxo de autorização de dispositivo
+type DeviceAuthorizationStatus string
+
+const (
+	DeviceAuthorizationPending  DeviceAuthorizationStatus = "pending"
+	DeviceAuthorizationApproved DeviceAuthorizationStatus = "approved"
+	DeviceAuthorizationConsumed DeviceAuthorizationStatus = "consumed" // token já entregue; o device_code não vale mais
+)
+
+// DeviceAuthorization é um pedido de pareamento aberto pelo robô (RFC 8628).
+// O robô guarda o device_code (aqui só o hash) e mostra o user_code, que o
+// dono aprova no app escolhendo o robô.
+type DeviceAuthorization struct {
+	ID             uuid.UUID                 `json:"id" gorm:"type:uuid;primaryKey"`
+	DeviceCodeHash string                    `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	UserCode       string                    `json:"user_code" gorm:"type:varchar(16);not null;index"`
+	Status         DeviceAuthorizationStatus `json:"status" gorm:"type:text;default:'pending'"`
+	Serial         string                    `json:"serial" gorm:"type:varchar(64)"` // informado pelo robô, só para o dono conferir
+	RobotID        *uuid.UUID                `json:"robot_id" gorm:"type:uuid"`
+	ApprovedBy     *uuid.UUID                `json:"approved_by" gorm:"type:uuid"`
+	ApprovedAt     *time.Time                `json:"approved_at"`
+	Interval       int                       `json:"interval"` // segundos mínimos entre consultas do robô
+	LastPolledAt   *time.Time                `json:"last_polled_at"`
+	ExpiresAt      time.Time                 `json:"expires_at" gorm:"not null;index"`
+	CreatedAt      time.Time                 `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time                 `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (a *DeviceAuthorization) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultHardwareModel identifica as animações usadas quando o modelo do robô
+// não tem mapeamento próprio
+const DefaultHardwareModel = "default"
+
+// Emotion é uma emoção que a IA pode devolver em "emocao". Aliases cobrem
+// variações comuns da saída do modelo (sinônimos, inglês, sem acento).
+type Emotion struct {
+	ID         string             `json:"id" gorm:"type:varchar(50);primaryKey"`
+	Name       string             `json:"name" gorm:"type:varchar(100);not null"`
+	Aliases    []string           `json:"aliases" gorm:"serializer:json"`
+	IsDefault  bool               `json:"is_default"` // usada quando a saída do modelo não é reconhecida
+	Animations []EmotionAnimation `json:"animations" gorm:"foreignKey:EmotionID"`
+	CreatedAt  time.Time          `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time          `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// EmotionAnimation diz como um modelo de robô expressa a emoção
+type EmotionAnimation struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	EmotionID     string    `json:"emotion_id" gorm:"type:varchar(50);not null;uniqueIndex:idx_emotion_hardware_model"`
+	HardwareModel string    `json:"hardware_model" gorm:"type:varchar(50);not null;uniqueIndex:idx_emotion_hardware_model"`
+	AnimationCode string    `json:"animation_code" gorm:"type:varchar(100)"`
+	LEDCode       string    `json:"led_code" gorm:"type:varchar(100)"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (a *EmotionAnimation) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MessageUsage conta as mensagens de um robô dentro de um período de cobrança.
+// Cada período gera uma nova linha, então o contador "zera" na renovação.
+type MessageUsage struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	RobotID      uuid.UUID `json:"robot_id" gorm:"type:uuid;not null;uniqueIndex:idx_message_usage_period"`
+	PeriodStart  time.Time `json:"period_start" gorm:"not null;uniqueIndex:idx_message_usage_period"`
+	PeriodEnd    time.Time `json:"period_end" gorm:"not null"`
+	PlanType     PlanType  `json:"plan_type" gorm:"type:text;not null"`
+	MessagesUsed int       `json:"messages_used" gorm:"default:0"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (u *MessageUsage) BeforeCreate(tx *gorm.DB) (err error) {
+	u.ID = uuid.New()
+	return
+}
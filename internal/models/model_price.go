@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ModelPrice é o preço por milhão de tokens de um modelo de IA. Model pode ser
+// um prefixo ("gpt-4o" cobre "gpt-4o-2024-08-06"); vence o mais específico.
+type ModelPrice struct {
+	ID                   uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Model                string    `json:"model" gorm:"type:varchar(100);uniqueIndex;not null"`
+	PromptPerMillion     float64   `json:"prompt_per_million"`
+	CompletionPerMillion float64   `json:"completion_per_million"`
+	Currency             string    `json:"currency" gorm:"type:varchar(3);default:'USD'"`
+	CreatedAt            time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (p *ModelPrice) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
+	return
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxStatus representa o estado de entrega de uma mensagem do outbox
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxSending   OutboxStatus = "sending"
+	OutboxDelivered OutboxStatus = "delivered"
+	OutboxDead      OutboxStatus = "dead" // esgotou as tentativas; só sai daqui com retry manual
+)
+
+// OutboxMessage é uma resposta a ser entregue ao servidor de display do robô.
+// É gravada junto com a conversa e enviada pelo dispatcher em segundo plano,
+// então nada se perde se o servidor estiver fora do ar.
+type OutboxMessage struct {
+	ID            uuid.UUID    `json:"id" gorm:"type:uuid;primaryKey"`
+	RobotID       uuid.UUID    `json:"robot_id" gorm:"type:uuid;not null;index"`
+	TargetURL     string       `json:"target_url" gorm:"type:varchar(255);not null"`
+	Payload       string       `json:"payload" gorm:"type:text"`
+	Status        OutboxStatus `json:"status" gorm:"type:text;default:'pending';index:idx_outbox_due"`
+	Attempts      int          `json:"attempts" gorm:"default:0"`
+	NextAttemptAt time.Time    `json:"next_attempt_at" gorm:"index:idx_outbox_due"`
+	LastError     string       `json:"last_error" gorm:"type:text"`
+	DeliveredAt   *time.Time   `json:"delivered_at"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (m *OutboxMessage) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	return
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PlanCatalog descreve um plano vendável: preço no Stripe, valor cobrado e
+// limites/funcionalidades liberados para o robô.
+type PlanCatalog struct {
+	ID            uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey"`
+	Code          PlanType        `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name          string          `json:"name" gorm:"type:varchar(255);not null"`
+	StripePriceID string          `json:"stripe_price_id" gorm:"type:varchar(255);index"`
+	Amount        int64           `json:"amount" gorm:"not null"` // em centavos
+	Currency      string          `json:"currency" gorm:"type:varchar(3);default:'BRL'"`
+	Interval      string          `json:"interval" gorm:"type:varchar(20);default:'month'"`
+	MessageLimit  int             `json:"message_limit"` // mensagens por período de cobrança, 0 = ilimitado
+	Features      map[string]bool `json:"features" gorm:"serializer:json"`
+	Active        bool            `json:"active"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Telemetria: dias guardados e menor intervalo (em segundos) de agregação nas consultas
+	TelemetryRetentionDays     int `json:"telemetry_retention_days" gorm:"default:7"`
+	TelemetryResolutionSeconds int `json:"telemetry_resolution_seconds" gorm:"default:300"`
+}
+
+func (PlanCatalog) TableName() string {
+	return "plan_catalog"
+}
+
+func (p *PlanCatalog) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
+	return
+}
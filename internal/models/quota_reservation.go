@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// QuotaReservationStatus representa o estado de uma reserva de cota
+type QuotaReservationStatus string
+
+const (
+	ReservationPending   QuotaReservationStatus = "reserved"
+	ReservationCommitted QuotaReservationStatus = "committed"
+	ReservationReleased  QuotaReservationStatus = "released"
+)
+
+// QuotaReservation é o lançamento de uma mensagem reservada no contador do período.
+// A mensagem já conta no MessageUsage enquanto a reserva está pendente; commit a
+// confirma e release a devolve. Reservas pendentes vencidas são liberadas pelo sweeper.
+type QuotaReservation struct {
+	ID        uuid.UUID              `json:"id" gorm:"type:uuid;primaryKey"`
+	UsageID   uuid.UUID              `json:"usage_id" gorm:"type:uuid;not null;index"`
+	RobotID   uuid.UUID              `json:"robot_id" gorm:"type:uuid;not null"`
+	Status    QuotaReservationStatus `json:"status" gorm:"type:text;not null;default:'reserved';index:idx_quota_reservation_pending"`
+	ExpiresAt time.Time              `json:"expires_at" gorm:"not null;index:idx_quota_reservation_pending"`
+	CreatedAt time.Time              `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time              `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (r *QuotaReservation) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CommandType é a ação pedida pelo dono ao robô
+type CommandType string
+
+const (
+	CommandSay    CommandType = "say" // payload: {"text": "..."}
+	CommandDance  CommandType = "dance"
+	CommandReboot CommandType = "reboot"
+)
+
+// CommandStatus representa o ciclo de vida de um comando
+type CommandStatus string
+
+const (
+	CommandQueued    CommandStatus = "queued"
+	CommandDelivered CommandStatus = "delivered" // entregue ao robô, aguardando confirmação
+	CommandAcked     CommandStatus = "acked"
+	CommandFailed    CommandStatus = "failed" // recusado pelo robô ou vencido (TTL)
+)
+
+// RobotCommand é um comando enviado pelo dono e buscado pelo robô. Vale até
+// ExpiresAt; depois disso, se não foi confirmado, vira failed.
+type RobotCommand struct {
+	ID          uuid.UUID              `json:"id" gorm:"type:uuid;primaryKey"`
+	RobotID     uuid.UUID              `json:"robot_id" gorm:"type:uuid;not null;index:idx_robot_command_pending"`
+	UserID      uuid.UUID              `json:"user_id" gorm:"type:uuid;not null"`
+	Type        CommandType            `json:"type" gorm:"type:varchar(30);not null"`
+	Payload     map[string]interface{} `json:"payload" gorm:"serializer:json"`
+	Status      CommandStatus          `json:"status" gorm:"type:text;default:'queued';index:idx_robot_command_pending"`
+	Result      string                 `json:"result" gorm:"type:text"` // resposta do robô na confirmação ou motivo da falha
+	ExpiresAt   time.Time              `json:"expires_at" gorm:"not null;index"`
+	DeliveredAt *time.Time             `json:"delivered_at"`
+	CompletedAt *time.Time             `json:"completed_at"`
+	CreatedAt   time.Time              `json:"created_at" gorm:"autoCreateTime;index:idx_robot_command_pending"`
+	UpdatedAt   time.Time              `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (c *RobotCommand) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RobotPresenceEvent registra cada transição online/offline de um robô
+type RobotPresenceEvent struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	RobotID   uuid.UUID `json:"robot_id" gorm:"type:uuid;not null;index:idx_robot_presence_robot_at"`
+	Online    bool      `json:"online"`
+	At        time.Time `json:"at" gorm:"not null;index:idx_robot_presence_robot_at"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (e *RobotPresenceEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New()
+	return
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RobotProfile personaliza como o robô conversa. Campos vazios herdam da
+// persona padrão indicada em DefaultVersion (ou da mais recente).
+type RobotProfile struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	RobotID         uuid.UUID `json:"robot_id" gorm:"type:uuid;uniqueIndex;not null"`
+	Robot           Robot     `json:"-" gorm:"foreignKey:RobotID"`
+	Persona         string    `json:"persona" gorm:"type:text"`
+	Language        string    `json:"language" gorm:"type:varchar(20)"`
+	Formality       string    `json:"formality" gorm:"type:varchar(20)"`       // casual, neutral, formal
+	AllowedEmotions []string  `json:"allowed_emotions" gorm:"serializer:json"` // subconjunto do catálogo de emoções; vazio = todas
+	ResponseLength  string    `json:"response_length" gorm:"type:varchar(20)"` // short, medium, long
+	DefaultVersion  string    `json:"default_version" gorm:"type:varchar(20)"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (p *RobotProfile) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
+	return
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RobotTokenSource indica por qual fluxo o token do robô foi emitido
+type RobotTokenSource string
+
+const (
+	TokenFromOwner     RobotTokenSource = "owner"     // POST /api/robots/{id}/token
+	TokenFromBootstrap RobotTokenSource = "bootstrap" // segredo de fábrica do dispositivo
+	TokenFromPairing   RobotTokenSource = "pairing"   // autorização de dispositivo (user_code)
+	TokenFromRotation  RobotTokenSource = "rotation"  // o próprio robô renovou o token
+)
+
+const (
+	TokenRevokedByOwner = "revoked"
+	TokenSuperseded     = "superseded"      // um token mais novo foi emitido para o robô
+	TokenDeviceReleased = "device_released" // o dispositivo que recebeu o token foi desvinculado
+)
+
+// RobotToken registra cada JWT emitido para um robô; o ID é o "jti" do token.
+// Só o token mais recente de cada robô vale: emitir outro revoga os anteriores.
+type RobotToken struct {
+	ID            uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey"`
+	RobotID       uuid.UUID        `json:"robot_id" gorm:"type:uuid;not null;index"`
+	Source        RobotTokenSource `json:"source" gorm:"type:varchar(20);not null"`
+	ExpiresAt     time.Time        `json:"expires_at" gorm:"not null"`
+	LastUsedAt    *time.Time       `json:"last_used_at"`
+	RevokedAt     *time.Time       `json:"revoked_at"`
+	RevokedReason string           `json:"revoked_reason" gorm:"type:varchar(20)"`
+	CreatedAt     time.Time        `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (t *RobotToken) BeforeCreate(tx *gorm.DB) (err error) {
+	t.ID = uuid.New()
+	return
+}
+
+// Active indica se o token ainda pode ser usado
+func (t *RobotToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
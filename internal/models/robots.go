@@ -15,8 +15,18 @@ const (
 	StatusSuspense RobotStatus = "suspense"
 )
 
+// SuspensionReason registra por que o robô foi suspenso, para que só a causa
+// resolvida desfaça a suspensão
+type SuspensionReason string
+
+const (
+	SuspendedForDunning      SuspensionReason = "dunning"        // período de graça vencido sem pagamento
+	SuspendedForPayment      SuspensionReason = "payment_failed" // pagamento avulso recusado
+	SuspendedForSubscription SuspensionReason = "subscription"   // assinatura cancelada, encerrada ou não paga no Stripe
+)
+
 type Robot struct {
-	ID             uuid.UUID `gorm:"type:uuid;primaryKey"` // device_id
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey"` // robô comprado; o aparelho físico vinculado fica em Device
 	Name           string
 	UserID         uuid.UUID `gorm:"type:uuid;not null"`
 	User           *User      `gorm:"foreignKey:UserID"`
@@ -24,8 +34,15 @@ type Robot struct {
 	Status         RobotStatus `gorm:"type:text;default:'pending'"`
 	PlanValidUntil *time.Time
 	LastPing       *time.Time `json:"ultimo_ping"`
+	HardwareModel  string     `gorm:"type:varchar(50)"` // modelo físico; define as animações de cada emoção
+	DisplayURL     string     `gorm:"type:varchar(255)"` // servidor de display do robô; vazio usa DISPLAY_SERVER_URL
+	Firmware       string     `gorm:"type:varchar(50)"` // versão informada no heartbeat
+	LastIP         string     `gorm:"type:varchar(64)"`
+	Online         bool       `gorm:"default:false;index"` // derivado do LastPing pelo sweeper de presença
 	CreatedAt      time.Time  `gorm:"autoCreateTime"`
 
+	SuspendedReason SuspensionReason `gorm:"type:varchar(30)"` // vazio quando ativo ou suspenso antes do registro do motivo
+
 	Plans []Plan `gorm:"foreignKey:RobotID"`
 }
 
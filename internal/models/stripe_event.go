@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// StripeEventStatus representa o status de processamento de um evento do Stripe
+type StripeEventStatus string
+
+const (
+	StripeEventPending    StripeEventStatus = "pending"
+	StripeEventProcessing StripeEventStatus = "processing"
+	StripeEventProcessed  StripeEventStatus = "processed"
+	StripeEventFailed     StripeEventStatus = "failed"
+)
+
+// StripeEvent guarda cada evento de webhook recebido, para que reenvios do
+// Stripe não sejam processados duas vezes e eventos com falha possam ser
+// reprocessados.
+type StripeEvent struct {
+	ID          string            `json:"id" gorm:"type:varchar(255);primaryKey"` // ID do evento no Stripe (evt_...)
+	Type        string            `json:"type" gorm:"type:varchar(255);index"`
+	Payload     string            `json:"payload" gorm:"type:text"`
+	Status      StripeEventStatus `json:"status" gorm:"type:text;default:'pending';index"`
+	Attempts    int               `json:"attempts" gorm:"default:0"`
+	ClaimedAt   *time.Time        `json:"claimed_at"` // início do processamento atual (lease)
+	LastError   string            `json:"last_error" gorm:"type:text"`
+	ProcessedAt *time.Time        `json:"processed_at"`
+	CreatedAt   time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -16,6 +16,7 @@ const (
 	SubscriptionCanceled SubscriptionStatus = "canceled"
 	SubscriptionExpired  SubscriptionStatus = "expired"
 	SubscriptionPending  SubscriptionStatus = "pending"
+	SubscriptionPastDue  SubscriptionStatus = "past_due" // renovação falhou, robô segue funcionando até o fim do período de graça
 )
 
 // Subscription representa uma assinatura de plano
@@ -33,6 +34,10 @@ type Subscription struct {
 	ProviderCustomerID     string             `gorm:"type:varchar(255)"` // Stripe customer ID
 	CancelAtPeriodEnd      bool               `gorm:"default:false"`
 	CanceledAt             *time.Time
+	PastDueSince           *time.Time // primeira falha de cobrança do ciclo atual
+	GracePeriodEndsAt      *time.Time // após essa data o robô é suspenso
+	FailedPaymentAttempts  int `gorm:"default:0"`
+	LastProviderEventAt    *time.Time // timestamp do último evento do Stripe aplicado (entrega fora de ordem)
 	CreatedAt              time.Time `gorm:"autoCreateTime"`
 	UpdatedAt              time.Time `gorm:"autoUpdateTime"`
 
@@ -45,8 +50,13 @@ func (s *Subscription) BeforeCreate(tx *gorm.DB) (err error) {
 	return
 }
 
-// IsActive verifica se a assinatura está ativa e dentro do período válido
+// IsActive verifica se a assinatura está ativa e dentro do período válido,
+// ou inadimplente mas ainda dentro do período de graça
 func (s *Subscription) IsActive() bool {
+	if s.InGracePeriod() {
+		return true
+	}
+
 	now := time.Now()
 	return s.Status == SubscriptionActive && 
 		   now.After(s.CurrentPeriodStart) && 
@@ -59,3 +69,10 @@ func (s *Subscription) ShouldRenew() bool {
 		   !s.CancelAtPeriodEnd && 
 		   time.Now().After(s.CurrentPeriodEnd.Add(-24*time.Hour)) // 1 dia antes do vencimento
 }
+
+// InGracePeriod verifica se a assinatura está inadimplente mas ainda dentro do período de graça
+func (s *Subscription) InGracePeriod() bool {
+	return s.Status == SubscriptionPastDue &&
+		s.GracePeriodEndsAt != nil &&
+		time.Now().Before(*s.GracePeriodEndsAt)
+}
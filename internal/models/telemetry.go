@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TelemetryPoint é uma leitura de sensor enviada pelo robô. RecordedAt fica em
+// milissegundos Unix para que a agregação por intervalo seja só aritmética inteira
+// no banco; ExpiresAt vem da retenção do plano no momento da ingestão.
+type TelemetryPoint struct {
+	ID         uint64    `json:"-" gorm:"primaryKey;autoIncrement"`
+	RobotID    uuid.UUID `json:"robot_id" gorm:"type:uuid;not null;index:idx_telemetry_robot_metric_time,priority:1"`
+	Metric     string    `json:"metric" gorm:"type:varchar(50);not null;index:idx_telemetry_robot_metric_time,priority:2"`
+	Value      float64   `json:"value"`
+	RecordedAt int64     `json:"recorded_at" gorm:"not null;index:idx_telemetry_robot_metric_time,priority:3"`
+	ExpiresAt  time.Time `json:"-" gorm:"not null;index"`
+}
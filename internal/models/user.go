@@ -7,12 +7,20 @@ import (
 	"gorm.io/gorm"
 )
 
+type UserRole string
+
+const (
+	RoleUser  UserRole = "user"
+	RoleAdmin UserRole = "admin"
+)
+
 type User struct {
 	ID           uuid.UUID `json:"id" db:"id" gorm:"type:uuid;primaryKey"`
 	Name         string    `json:"name" db:"name" gorm:"type:varchar(255);not null"`
 	Email        string    `json:"email" db:"email" gorm:"type:varchar(255);unique;not null"`
 	Password     string    `json:"-" db:"password" gorm:"type:varchar(255);not null"` // hash, n exposto no JSON
-	MessagesUsed uint      `json:"messages_used" gorm:"default:0"`
+	Role         UserRole  `json:"role" db:"role" gorm:"type:text;default:'user'"`
+	MessagesUsed uint      `json:"messages_used" gorm:"default:0"` // total histórico; a cota fica em MessageUsage
 	Robots       []Robot   `json:"robots" gorm:"foreignKey:UserID"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at" gorm:"autoUpdateTime"`
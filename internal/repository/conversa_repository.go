@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type ConversaRepository interface {
+	CreateSession(session *models.ConversaSession) error
+	FindCurrentSession(roboID uuid.UUID) (*models.ConversaSession, error)
+	EndSessions(roboID uuid.UUID, endedAt time.Time) error
+	FindRecentLogs(sessionID uuid.UUID, limit int) ([]models.ConversaLog, error)
+}
+
+type conversaRepository struct {
+	db *gorm.DB
+}
+
+func NewConversaRepository(db *gorm.DB) ConversaRepository {
+	return &conversaRepository{db: db}
+}
+
+func (r *conversaRepository) CreateSession(session *models.ConversaSession) error {
+	return r.db.Create(session).Error
+}
+
+// FindCurrentSession retorna a sessão em aberto do robô, ou nil se não houver
+func (r *conversaRepository) FindCurrentSession(roboID uuid.UUID) (*models.ConversaSession, error) {
+	var session models.ConversaSession
+	err := r.db.Where("robo_id = ? AND ended_at IS NULL", roboID).
+		Order("created_at DESC").
+		First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *conversaRepository) EndSessions(roboID uuid.UUID, endedAt time.Time) error {
+	return r.db.Model(&models.ConversaSession{}).
+		Where("robo_id = ? AND ended_at IS NULL", roboID).
+		Update("ended_at", endedAt).Error
+}
+
+// FindRecentLogs retorna as últimas mensagens da sessão, da mais recente para a mais antiga
+func (r *conversaRepository) FindRecentLogs(sessionID uuid.UUID, limit int) ([]models.ConversaLog, error) {
+	var logs []models.ConversaLog
+	err := r.db.Where("session_id = ?", sessionID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
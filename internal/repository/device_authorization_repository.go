@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type DeviceAuthorizationRepository interface {
+	Create(authorization *models.DeviceAuthorization) error
+	FindByDeviceCodeHash(hash string) (*models.DeviceAuthorization, error)
+	FindPendingByUserCode(userCode string, now time.Time) (*models.DeviceAuthorization, error)
+	Approve(id, robotID, userID uuid.UUID, now time.Time) (bool, error)
+	RecordPoll(id uuid.UUID, at time.Time, interval int) error
+	Consume(id uuid.UUID) (bool, error)
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+type deviceAuthorizationRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceAuthorizationRepository(db *gorm.DB) DeviceAuthorizationRepository {
+	return &deviceAuthorizationRepository{db: db}
+}
+
+func (r *deviceAuthorizationRepository) Create(authorization *models.DeviceAuthorization) error {
+	return r.db.Create(authorization).Error
+}
+
+func (r *deviceAuthorizationRepository) FindByDeviceCodeHash(hash string) (*models.DeviceAuthorization, error) {
+	var authorization models.DeviceAuthorization
+	if err := r.db.Where("device_code_hash = ?", hash).First(&authorization).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &authorization, nil
+}
+
+// FindPendingByUserCode busca só pedidos pendentes e dentro da validade: o mesmo
+// user_code pode se repetir em pedidos antigos
+func (r *deviceAuthorizationRepository) FindPendingByUserCode(userCode string, now time.Time) (*models.DeviceAuthorization, error) {
+	var authorization models.DeviceAuthorization
+	err := r.db.Where("user_code = ? AND status = ? AND expires_at > ?", userCode, models.DeviceAuthorizationPending, now).
+		First(&authorization).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &authorization, nil
+}
+
+// Approve associa o pedido pendente ao robô escolhido pelo dono. Retorna false
+// se o pedido já foi aprovado ou venceu nesse meio tempo.
+func (r *deviceAuthorizationRepository) Approve(id, robotID, userID uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.Model(&models.DeviceAuthorization{}).
+		Where("id = ? AND status = ? AND expires_at > ?", id, models.DeviceAuthorizationPending, now).
+		Updates(map[string]interface{}{
+			"status":      models.DeviceAuthorizationApproved,
+			"robot_id":    robotID,
+			"approved_by": userID,
+			"approved_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *deviceAuthorizationRepository) RecordPoll(id uuid.UUID, at time.Time, interval int) error {
+	return r.db.Model(&models.DeviceAuthorization{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_polled_at": at,
+		"interval":       interval,
+	}).Error
+}
+
+// Consume marca o pedido aprovado como usado; o token só sai uma vez
+func (r *deviceAuthorizationRepository) Consume(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.DeviceAuthorization{}).
+		Where("id = ? AND status = ?", id, models.DeviceAuthorizationApproved).
+		Update("status", models.DeviceAuthorizationConsumed)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *deviceAuthorizationRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&models.DeviceAuthorization{})
+	return result.RowsAffected, result.Error
+}
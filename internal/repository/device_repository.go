@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type DeviceRepository interface {
+	CreateBatch(devices []models.Device) error
+	FindExistingSerials(serials []string) ([]string, error)
+	FindBySerial(serial string) (*models.Device, error)
+	FindByRobotID(robotID uuid.UUID) (*models.Device, error)
+	FindAll(status models.DeviceStatus, limit int) ([]models.Device, error)
+	Claim(id, robotID, userID uuid.UUID) (bool, error)
+	Release(id, robotID uuid.UUID) error
+	RecordFailedClaim(id uuid.UUID) error
+	ResetClaimAttempts(id uuid.UUID) error
+	RecordBootstrap(id uuid.UUID, at time.Time) error
+}
+
+type deviceRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepository(db *gorm.DB) DeviceRepository {
+	return &deviceRepository{db: db}
+}
+
+// CreateBatch grava o lote inteiro numa transação: um serial repetido cancela tudo
+func (r *deviceRepository) CreateBatch(devices []models.Device) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(devices, 100).Error
+	})
+}
+
+func (r *deviceRepository) FindExistingSerials(serials []string) ([]string, error) {
+	var existing []string
+	err := r.db.Model(&models.Device{}).Where("serial IN ?", serials).Pluck("serial", &existing).Error
+	return existing, err
+}
+
+func (r *deviceRepository) FindBySerial(serial string) (*models.Device, error) {
+	var device models.Device
+	if err := r.db.Where("serial = ?", serial).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) FindByRobotID(robotID uuid.UUID) (*models.Device, error) {
+	var device models.Device
+	if err := r.db.Where("robot_id = ?", robotID).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) FindAll(status models.DeviceStatus, limit int) ([]models.Device, error) {
+	var devices []models.Device
+	query := r.db.Order("created_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Claim vincula o dispositivo ao robô. A troca é condicional: retorna false se
+// outro usuário vinculou o dispositivo no meio do caminho.
+func (r *deviceRepository) Claim(id, robotID, userID uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.Device{}).
+		Where("id = ? AND status = ?", id, models.DeviceUnclaimed).
+		Updates(map[string]interface{}{
+			"status":         models.DeviceClaimed,
+			"robot_id":       robotID,
+			"claimed_by":     userID,
+			"claimed_at":     time.Now(),
+			"claim_attempts": 0,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Release desfaz o vínculo; o mesmo código da caixa volta a valer para o próximo dono.
+// Na mesma transação revoga os tokens ainda válidos do robô, que o aparelho
+// recebeu pelo bootstrap ou pareamento e levaria junto numa revenda.
+func (r *deviceRepository) Release(id, robotID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Device{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":     models.DeviceUnclaimed,
+			"robot_id":   nil,
+			"claimed_by": nil,
+			"claimed_at": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.RobotToken{}).
+			Where("robot_id = ? AND revoked_at IS NULL", robotID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"revoked_reason": models.TokenDeviceReleased,
+			}).Error
+	})
+}
+
+func (r *deviceRepository) RecordFailedClaim(id uuid.UUID) error {
+	return r.db.Model(&models.Device{}).Where("id = ?", id).
+		Update("claim_attempts", gorm.Expr("claim_attempts + 1")).Error
+}
+
+func (r *deviceRepository) ResetClaimAttempts(id uuid.UUID) error {
+	return r.db.Model(&models.Device{}).Where("id = ?", id).Update("claim_attempts", 0).Error
+}
+
+func (r *deviceRepository) RecordBootstrap(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.Device{}).Where("id = ?", id).Update("last_bootstrap_at", at).Error
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmotionRepository interface {
+	Create(emotion *models.Emotion) error
+	Update(emotion *models.Emotion) error
+	Delete(id string) error
+	FindAll() ([]models.Emotion, error)
+	FindByID(id string) (*models.Emotion, error)
+	Count() (int64, error)
+	ClearDefault() error
+	FindAnimation(emotionID, hardwareModel string) (*models.EmotionAnimation, error)
+	SaveAnimation(animation *models.EmotionAnimation) error
+	DeleteAnimation(emotionID, hardwareModel string) (bool, error)
+}
+
+type emotionRepository struct {
+	db *gorm.DB
+}
+
+func NewEmotionRepository(db *gorm.DB) EmotionRepository {
+	return &emotionRepository{db: db}
+}
+
+func (r *emotionRepository) Create(emotion *models.Emotion) error {
+	return r.db.Create(emotion).Error
+}
+
+func (r *emotionRepository) Update(emotion *models.Emotion) error {
+	return r.db.Omit(clause.Associations).Save(emotion).Error
+}
+
+func (r *emotionRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("emotion_id = ?", id).Delete(&models.EmotionAnimation{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&models.Emotion{}).Error
+	})
+}
+
+func (r *emotionRepository) FindAll() ([]models.Emotion, error) {
+	var emotions []models.Emotion
+	if err := r.db.Preload("Animations").Order("id ASC").Find(&emotions).Error; err != nil {
+		return nil, err
+	}
+	return emotions, nil
+}
+
+func (r *emotionRepository) FindByID(id string) (*models.Emotion, error) {
+	var emotion models.Emotion
+	if err := r.db.Preload("Animations").Where("id = ?", id).First(&emotion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &emotion, nil
+}
+
+func (r *emotionRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Emotion{}).Count(&count).Error
+	return count, err
+}
+
+// ClearDefault desmarca a emoção padrão atual antes de eleger outra
+func (r *emotionRepository) ClearDefault() error {
+	return r.db.Model(&models.Emotion{}).Where("is_default = ?", true).Update("is_default", false).Error
+}
+
+func (r *emotionRepository) FindAnimation(emotionID, hardwareModel string) (*models.EmotionAnimation, error) {
+	var animation models.EmotionAnimation
+	if err := r.db.Where("emotion_id = ? AND hardware_model = ?", emotionID, hardwareModel).First(&animation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &animation, nil
+}
+
+func (r *emotionRepository) SaveAnimation(animation *models.EmotionAnimation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "emotion_id"}, {Name: "hardware_model"}},
+		DoUpdates: clause.AssignmentColumns([]string{"animation_code", "led_code", "updated_at"}),
+	}).Create(animation).Error
+}
+
+func (r *emotionRepository) DeleteAnimation(emotionID, hardwareModel string) (bool, error) {
+	result := r.db.Where("emotion_id = ? AND hardware_model = ?", emotionID, hardwareModel).Delete(&models.EmotionAnimation{})
+	return result.RowsAffected > 0, result.Error
+}
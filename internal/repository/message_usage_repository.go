@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MessageUsageRepository interface {
+	FindOrCreate(robotID uuid.UUID, periodStart, periodEnd time.Time, planType models.PlanType) (*models.MessageUsage, error)
+	Reserve(usage *models.MessageUsage, limit int, expiresAt time.Time) (*models.QuotaReservation, error)
+	ReleaseReservation(id uuid.UUID) (bool, error)
+	FindExpiredReservations(now time.Time, limit int) ([]models.QuotaReservation, error)
+}
+
+type messageUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewMessageUsageRepository(db *gorm.DB) MessageUsageRepository {
+	return &messageUsageRepository{db: db}
+}
+
+// FindOrCreate retorna o contador do período, criando-o na primeira mensagem.
+// O índice único (robot_id, period_start) evita duplicatas em requisições simultâneas.
+func (r *messageUsageRepository) FindOrCreate(robotID uuid.UUID, periodStart, periodEnd time.Time, planType models.PlanType) (*models.MessageUsage, error) {
+	usage := &models.MessageUsage{
+		RobotID:     robotID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		PlanType:    planType,
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(usage).Error; err != nil {
+		return nil, err
+	}
+
+	var existing models.MessageUsage
+	if err := r.db.Where("robot_id = ? AND period_start = ?", robotID, periodStart).First(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	// Upgrade/downgrade no meio do período mantém o contador, mas troca o plano.
+	// Só as duas colunas são gravadas: um Save da linha inteira devolveria um
+	// messages_used lido antes de reservas simultâneas.
+	if existing.PlanType != planType || !existing.PeriodEnd.Equal(periodEnd) {
+		err := r.db.Model(&existing).Updates(map[string]interface{}{
+			"plan_type":  planType,
+			"period_end": periodEnd,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &existing, nil
+}
+
+// Reserve consome uma mensagem do contador e registra a reserva na mesma transação.
+// O UPDATE condicional é atômico tanto no SQLite quanto no Postgres, então requisições
+// simultâneas nunca passam do limite. Retorna nil, nil quando a cota está esgotada.
+func (r *messageUsageRepository) Reserve(usage *models.MessageUsage, limit int, expiresAt time.Time) (*models.QuotaReservation, error) {
+	var reservation *models.QuotaReservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.MessageUsage{}).Where("id = ?", usage.ID)
+		if limit > 0 {
+			query = query.Where("messages_used < ?", limit)
+		}
+		result := query.Update("messages_used", gorm.Expr("messages_used + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		reservation = &models.QuotaReservation{
+			UsageID:   usage.ID,
+			RobotID:   usage.RobotID,
+			Status:    models.ReservationPending,
+			ExpiresAt: expiresAt,
+		}
+		return tx.Create(reservation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// ReleaseReservation devolve a mensagem de uma reserva ainda pendente. A troca de
+// status é condicional, então uma reserva nunca é devolvida duas vezes nem depois
+// de confirmada. Retorna false se a reserva não estava mais pendente.
+func (r *messageUsageRepository) ReleaseReservation(id uuid.UUID) (bool, error) {
+	released := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Escreve antes de ler: no SQLite a transação já começa com o lock de escrita
+		result := tx.Model(&models.QuotaReservation{}).
+			Where("id = ? AND status = ?", id, models.ReservationPending).
+			Update("status", models.ReservationReleased)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var reservation models.QuotaReservation
+		if err := tx.Where("id = ?", id).First(&reservation).Error; err != nil {
+			return err
+		}
+
+		released = true
+		return tx.Model(&models.MessageUsage{}).Where("id = ? AND messages_used > 0", reservation.UsageID).
+			Update("messages_used", gorm.Expr("messages_used - 1")).Error
+	})
+	return released, err
+}
+
+func (r *messageUsageRepository) FindExpiredReservations(now time.Time, limit int) ([]models.QuotaReservation, error) {
+	var reservations []models.QuotaReservation
+	err := r.db.Where("status = ? AND expires_at < ?", models.ReservationPending, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&reservations).Error
+	return reservations, err
+}
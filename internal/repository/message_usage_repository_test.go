@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/db"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+)
+
+// TestReserveNeverExceedsLimit dispara mais reservas simultâneas do que a cota
+// permite contra um SQLite real, com trocas de plano no meio: exatamente limit
+// delas passam e o contador nunca fica acima do limite nem volta atrás.
+func TestReserveNeverExceedsLimit(t *testing.T) {
+	const (
+		limit    = 10
+		requests = 50
+	)
+
+	database, err := db.InitDB("sqlite", filepath.Join(t.TempDir(), "usage.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	if err := database.AutoMigrate(&models.MessageUsage{}, &models.QuotaReservation{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+
+	repo := NewMessageUsageRepository(database)
+	periodStart := time.Now().Truncate(time.Second)
+	usage, err := repo.FindOrCreate(uuid.New(), periodStart, periodStart.AddDate(0, 1, 0), models.BasicPlan)
+	if err != nil {
+		t.Fatalf("FindOrCreate: %v", err)
+	}
+
+	// Acompanha o contador enquanto as reservas acontecem
+	var maxUsed int64
+	stop := make(chan struct{})
+	watcher := make(chan struct{})
+	go func() {
+		defer close(watcher)
+		for {
+			var current models.MessageUsage
+			if err := database.Where("id = ?", usage.ID).First(&current).Error; err == nil {
+				for {
+					seen := atomic.LoadInt64(&maxUsed)
+					if int64(current.MessagesUsed) <= seen || atomic.CompareAndSwapInt64(&maxUsed, seen, int64(current.MessagesUsed)) {
+						break
+					}
+				}
+			}
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		reserved int64
+		start    = make(chan struct{})
+		errs     = make(chan error, requests+1)
+	)
+
+	// Upgrade/downgrade durante as reservas: FindOrCreate não pode devolver ao
+	// banco um messages_used antigo
+	changing := make(chan struct{})
+	changer := make(chan struct{})
+	go func() {
+		defer close(changer)
+		<-start
+		plans := []models.PlanType{models.PremiumPlan, models.BasicPlan}
+		for i := 0; ; i++ {
+			select {
+			case <-changing:
+				return
+			default:
+			}
+			if _, err := repo.FindOrCreate(usage.RobotID, periodStart, periodStart.AddDate(0, 1, i%2), plans[i%2]); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			reservation, err := repo.Reserve(usage, limit, time.Now().Add(time.Minute))
+			if err != nil {
+				errs <- err
+				return
+			}
+			if reservation != nil {
+				atomic.AddInt64(&reserved, 1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(changing)
+	<-changer
+	close(stop)
+	<-watcher
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Reserve: %v", err)
+	}
+	if reserved != limit {
+		t.Errorf("reservas aceitas = %d, esperado %d", reserved, limit)
+	}
+	if maxUsed > limit {
+		t.Errorf("messages_used chegou a %d, acima do limite %d", maxUsed, limit)
+	}
+
+	var final models.MessageUsage
+	if err := database.Where("id = ?", usage.ID).First(&final).Error; err != nil {
+		t.Fatalf("buscar contador: %v", err)
+	}
+	if final.MessagesUsed != limit {
+		t.Errorf("messages_used final = %d, esperado %d", final.MessagesUsed, limit)
+	}
+
+	var pending int64
+	database.Model(&models.QuotaReservation{}).Where("usage_id = ?", usage.ID).Count(&pending)
+	if pending != limit {
+		t.Errorf("reservas gravadas = %d, esperado %d", pending, limit)
+	}
+}
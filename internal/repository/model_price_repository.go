@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ModelPriceRepository interface {
+	FindAll() ([]models.ModelPrice, error)
+	FindByModel(model string) (*models.ModelPrice, error)
+	Upsert(price *models.ModelPrice) error
+	Delete(model string) (bool, error)
+	Count() (int64, error)
+}
+
+type modelPriceRepository struct {
+	db *gorm.DB
+}
+
+func NewModelPriceRepository(db *gorm.DB) ModelPriceRepository {
+	return &modelPriceRepository{db: db}
+}
+
+func (r *modelPriceRepository) FindAll() ([]models.ModelPrice, error) {
+	var prices []models.ModelPrice
+	if err := r.db.Order("model ASC").Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+func (r *modelPriceRepository) FindByModel(model string) (*models.ModelPrice, error) {
+	var price models.ModelPrice
+	if err := r.db.Where("model = ?", model).First(&price).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &price, nil
+}
+
+// Upsert cria o preço do modelo ou atualiza o existente
+func (r *modelPriceRepository) Upsert(price *models.ModelPrice) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "model"}},
+		DoUpdates: clause.AssignmentColumns([]string{"prompt_per_million", "completion_per_million", "currency", "updated_at"}),
+	}).Create(price).Error
+}
+
+func (r *modelPriceRepository) Delete(model string) (bool, error) {
+	result := r.db.Where("model = ?", model).Delete(&models.ModelPrice{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *modelPriceRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ModelPrice{}).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type OutboxRepository interface {
+	Create(message *models.OutboxMessage) error
+	FindByID(id uuid.UUID) (*models.OutboxMessage, error)
+	FindAll(status models.OutboxStatus, robotID *uuid.UUID, limit int) ([]models.OutboxMessage, error)
+	FindDue(now time.Time, limit int) ([]models.OutboxMessage, error)
+	Claim(id uuid.UUID, now, leaseUntil time.Time) (bool, error)
+	MarkDelivered(id uuid.UUID) error
+	MarkFailed(id uuid.UUID, status models.OutboxStatus, nextAttemptAt time.Time, cause error) error
+	Retry(id uuid.UUID) (bool, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Create(message *models.OutboxMessage) error {
+	return r.db.Create(message).Error
+}
+
+func (r *outboxRepository) FindByID(id uuid.UUID) (*models.OutboxMessage, error) {
+	var message models.OutboxMessage
+	if err := r.db.Where("id = ?", id).First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *outboxRepository) FindAll(status models.OutboxStatus, robotID *uuid.UUID, limit int) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	query := r.db.Order("created_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if robotID != nil {
+		query = query.Where("robot_id = ?", *robotID)
+	}
+	if err := query.Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// FindDue retorna as mensagens prontas para envio, incluindo as que ficaram em
+// "sending" depois que o lease venceu (o processo caiu no meio da entrega)
+func (r *outboxRepository) FindDue(now time.Time, limit int) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	err := r.db.Where("status IN ? AND next_attempt_at <= ?", []models.OutboxStatus{models.OutboxPending, models.OutboxSending}, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// Claim reserva a mensagem para uma entrega até leaseUntil. Retorna false se
+// outro dispatcher já a pegou.
+func (r *outboxRepository) Claim(id uuid.UUID, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&models.OutboxMessage{}).
+		Where("id = ? AND status IN ? AND next_attempt_at <= ?", id, []models.OutboxStatus{models.OutboxPending, models.OutboxSending}, now).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxSending,
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": leaseUntil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *outboxRepository) MarkDelivered(id uuid.UUID) error {
+	return r.db.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.OutboxDelivered,
+		"last_error":   "",
+		"delivered_at": time.Now(),
+	}).Error
+}
+
+func (r *outboxRepository) MarkFailed(id uuid.UUID, status models.OutboxStatus, nextAttemptAt time.Time, cause error) error {
+	return r.db.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      cause.Error(),
+	}).Error
+}
+
+// Retry recoloca na fila, com as tentativas zeradas, uma mensagem aguardando
+// nova tentativa ou morta. Mensagens entregues ou em envio não são alteradas.
+func (r *outboxRepository) Retry(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.OutboxMessage{}).
+		Where("id = ? AND status IN ?", id, []models.OutboxStatus{models.OutboxPending, models.OutboxDead}).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/peruccii/roadmap-go-backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PaymentRepository interface {
@@ -73,5 +74,5 @@ func (r *paymentRepository) UpdateStatus(id uuid.UUID, status models.PaymentStat
 }
 
 func (r *paymentRepository) Update(payment *models.Payment) error {
-	return r.db.Save(payment).Error
+	return r.db.Omit(clause.Associations).Save(payment).Error
 }
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type PlanCatalogRepository interface {
+	Create(plan *models.PlanCatalog) error
+	Update(plan *models.PlanCatalog) error
+	FindAll(activeOnly bool) ([]models.PlanCatalog, error)
+	FindByCode(code string) (*models.PlanCatalog, error)
+	FindByStripePriceID(priceID string) (*models.PlanCatalog, error)
+	Count() (int64, error)
+}
+
+type planCatalogRepository struct {
+	db *gorm.DB
+}
+
+func NewPlanCatalogRepository(db *gorm.DB) PlanCatalogRepository {
+	return &planCatalogRepository{db: db}
+}
+
+func (r *planCatalogRepository) Create(plan *models.PlanCatalog) error {
+	return r.db.Create(plan).Error
+}
+
+func (r *planCatalogRepository) Update(plan *models.PlanCatalog) error {
+	return r.db.Save(plan).Error
+}
+
+func (r *planCatalogRepository) FindAll(activeOnly bool) ([]models.PlanCatalog, error) {
+	var plans []models.PlanCatalog
+	query := r.db.Order("amount ASC")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	if err := query.Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (r *planCatalogRepository) FindByCode(code string) (*models.PlanCatalog, error) {
+	var plan models.PlanCatalog
+	if err := r.db.Where("code = ?", code).First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *planCatalogRepository) FindByStripePriceID(priceID string) (*models.PlanCatalog, error) {
+	var plan models.PlanCatalog
+	if err := r.db.Where("stripe_price_id = ?", priceID).First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *planCatalogRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PlanCatalog{}).Count(&count).Error
+	return count, err
+}
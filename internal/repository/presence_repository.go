@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type PresenceRepository interface {
+	RecordHeartbeat(robotID uuid.UUID, firmware, ip string, at time.Time) error
+	SetOnline(robotID uuid.UUID, online bool, at time.Time) (bool, error)
+	FindStaleOnline(cutoff time.Time) ([]uuid.UUID, error)
+	FindFreshOffline(cutoff time.Time) ([]uuid.UUID, error)
+	FindEvents(robotID uuid.UUID, limit int) ([]models.RobotPresenceEvent, error)
+}
+
+type presenceRepository struct {
+	db *gorm.DB
+}
+
+func NewPresenceRepository(db *gorm.DB) PresenceRepository {
+	return &presenceRepository{db: db}
+}
+
+func (r *presenceRepository) RecordHeartbeat(robotID uuid.UUID, firmware, ip string, at time.Time) error {
+	updates := map[string]interface{}{
+		"last_ping": at,
+		"last_ip":   ip,
+	}
+	// Firmware omitido no heartbeat mantém a versão conhecida
+	if firmware != "" {
+		updates["firmware"] = firmware
+	}
+	return r.db.Model(&models.Robot{}).Where("id = ?", robotID).Updates(updates).Error
+}
+
+// SetOnline troca o status de presença e registra a transição. A troca é
+// condicional, então retorna false (sem registrar nada) se o robô já estava no status.
+func (r *presenceRepository) SetOnline(robotID uuid.UUID, online bool, at time.Time) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Robot{}).
+			Where("id = ? AND online = ?", robotID, !online).
+			Update("online", online)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		changed = true
+		return tx.Create(&models.RobotPresenceEvent{RobotID: robotID, Online: online, At: at}).Error
+	})
+	return changed, err
+}
+
+// FindStaleOnline retorna os robôs marcados online sem sinal desde cutoff
+func (r *presenceRepository) FindStaleOnline(cutoff time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.Robot{}).
+		Where("online = ? AND (last_ping IS NULL OR last_ping < ?)", true, cutoff).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FindFreshOffline retorna os robôs marcados offline que deram sinal depois de cutoff
+func (r *presenceRepository) FindFreshOffline(cutoff time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.Robot{}).
+		Where("online = ? AND last_ping >= ?", false, cutoff).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *presenceRepository) FindEvents(robotID uuid.UUID, limit int) ([]models.RobotPresenceEvent, error) {
+	var events []models.RobotPresenceEvent
+	err := r.db.Where("robot_id = ?", robotID).Order("at DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AIUsageAggregate é a soma de uso de IA de um grupo de conversas
+type AIUsageAggregate struct {
+	Key              string
+	Messages         int64
+	PromptTokens     int64
+	CompletionTokens int64
+	Cost             float64
+}
+
+// RevenueAggregate é a soma dos pagamentos concluídos de um plano
+type RevenueAggregate struct {
+	PlanType string
+	Currency string
+	Payments int64
+	Revenue  int64
+}
+
+type ReportRepository interface {
+	AIUsageByRobot(from, to time.Time) ([]AIUsageAggregate, error)
+	AIUsageByUser(from, to time.Time) ([]AIUsageAggregate, error)
+	AIUsageByPlan(from, to time.Time) ([]AIUsageAggregate, error)
+	AIUsageTotal(from, to time.Time) (*AIUsageAggregate, error)
+	RevenueByPlan(from, to time.Time) ([]RevenueAggregate, error)
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+const aiUsageColumns = "COUNT(*) AS messages, COALESCE(SUM(conversa_logs.prompt_tokens), 0) AS prompt_tokens, " +
+	"COALESCE(SUM(conversa_logs.completion_tokens), 0) AS completion_tokens, COALESCE(SUM(conversa_logs.custo), 0) AS cost"
+
+// conversas no intervalo [from, to)
+func (r *reportRepository) conversas(from, to time.Time) *gorm.DB {
+	return r.db.Model(&models.ConversaLog{}).
+		Where("conversa_logs.created_at >= ? AND conversa_logs.created_at < ?", from, to)
+}
+
+func (r *reportRepository) AIUsageByRobot(from, to time.Time) ([]AIUsageAggregate, error) {
+	var rows []AIUsageAggregate
+	err := r.conversas(from, to).
+		Select("conversa_logs.robo_id AS key, " + aiUsageColumns).
+		Group("conversa_logs.robo_id").
+		Order("cost DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepository) AIUsageByUser(from, to time.Time) ([]AIUsageAggregate, error) {
+	var rows []AIUsageAggregate
+	err := r.conversas(from, to).
+		Select("robots.user_id AS key, " + aiUsageColumns).
+		Joins("JOIN robots ON robots.id = conversa_logs.robo_id").
+		Group("robots.user_id").
+		Order("cost DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// AIUsageByPlan agrupa pelo plano gravado no log; conversas anteriores ao registro ficam em "unknown"
+func (r *reportRepository) AIUsageByPlan(from, to time.Time) ([]AIUsageAggregate, error) {
+	var rows []AIUsageAggregate
+	err := r.conversas(from, to).
+		Select("COALESCE(NULLIF(conversa_logs.plan_type, ''), 'unknown') AS key, " + aiUsageColumns).
+		Group("COALESCE(NULLIF(conversa_logs.plan_type, ''), 'unknown')").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepository) AIUsageTotal(from, to time.Time) (*AIUsageAggregate, error) {
+	var total AIUsageAggregate
+	if err := r.conversas(from, to).Select(aiUsageColumns).Scan(&total).Error; err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
+
+// RevenueByPlan soma os pagamentos concluídos, ligando cada um à assinatura do Stripe para achar o plano
+func (r *reportRepository) RevenueByPlan(from, to time.Time) ([]RevenueAggregate, error) {
+	var rows []RevenueAggregate
+	err := r.db.Model(&models.Payment{}).
+		Select("COALESCE(subscriptions.plan_type, 'unknown') AS plan_type, payments.currency AS currency, "+
+			"COUNT(*) AS payments, COALESCE(SUM(payments.amount), 0) AS revenue").
+		Joins("LEFT JOIN subscriptions ON subscriptions.provider_subscription_id = payments.provider_subscription_id AND payments.provider_subscription_id <> ''").
+		Where("payments.status = ? AND payments.created_at >= ? AND payments.created_at < ?", models.PaymentCompleted, from, to).
+		Group("COALESCE(subscriptions.plan_type, 'unknown'), payments.currency").
+		Scan(&rows).Error
+	return rows, err
+}
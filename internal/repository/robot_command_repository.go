@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type RobotCommandRepository interface {
+	Create(command *models.RobotCommand) error
+	FindByIDAndRobotID(id, robotID uuid.UUID) (*models.RobotCommand, error)
+	FindByRobotID(robotID uuid.UUID, status models.CommandStatus, limit int) ([]models.RobotCommand, error)
+	FindDeliverable(robotID uuid.UUID, now, redeliverBefore time.Time, limit int) ([]models.RobotCommand, error)
+	MarkDelivered(id uuid.UUID, now, redeliverBefore time.Time) (bool, error)
+	Complete(id, robotID uuid.UUID, status models.CommandStatus, result string) (bool, error)
+	ExpirePending(now time.Time) (int64, error)
+}
+
+type robotCommandRepository struct {
+	db *gorm.DB
+}
+
+func NewRobotCommandRepository(db *gorm.DB) RobotCommandRepository {
+	return &robotCommandRepository{db: db}
+}
+
+func (r *robotCommandRepository) Create(command *models.RobotCommand) error {
+	return r.db.Create(command).Error
+}
+
+func (r *robotCommandRepository) FindByIDAndRobotID(id, robotID uuid.UUID) (*models.RobotCommand, error) {
+	var command models.RobotCommand
+	if err := r.db.Where("id = ? AND robot_id = ?", id, robotID).First(&command).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &command, nil
+}
+
+func (r *robotCommandRepository) FindByRobotID(robotID uuid.UUID, status models.CommandStatus, limit int) ([]models.RobotCommand, error) {
+	var commands []models.RobotCommand
+	query := r.db.Where("robot_id = ?", robotID).Order("created_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&commands).Error; err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// deliverableCommands filtra os comandos ainda válidos que estão na fila ou que foram
+// entregues antes de redeliverBefore sem confirmação (a resposta ao robô se perdeu)
+func deliverableCommands(query *gorm.DB, now, redeliverBefore time.Time) *gorm.DB {
+	return query.Where("expires_at > ? AND (status = ? OR (status = ? AND delivered_at < ?))",
+		now, models.CommandQueued, models.CommandDelivered, redeliverBefore)
+}
+
+// FindDeliverable retorna os comandos a entregar ao robô, do mais antigo ao mais novo
+func (r *robotCommandRepository) FindDeliverable(robotID uuid.UUID, now, redeliverBefore time.Time, limit int) ([]models.RobotCommand, error) {
+	var commands []models.RobotCommand
+	err := deliverableCommands(r.db.Where("robot_id = ?", robotID), now, redeliverBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&commands).Error
+	return commands, err
+}
+
+// MarkDelivered marca o comando como entregue. Retorna false se outra requisição
+// do robô já o pegou.
+func (r *robotCommandRepository) MarkDelivered(id uuid.UUID, now, redeliverBefore time.Time) (bool, error) {
+	result := deliverableCommands(r.db.Model(&models.RobotCommand{}).Where("id = ?", id), now, redeliverBefore).
+		Updates(map[string]interface{}{
+			"status":       models.CommandDelivered,
+			"delivered_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Complete registra a confirmação (acked ou failed) de um comando entregue.
+// Retorna false se o comando não está aguardando confirmação.
+func (r *robotCommandRepository) Complete(id, robotID uuid.UUID, status models.CommandStatus, result string) (bool, error) {
+	update := r.db.Model(&models.RobotCommand{}).
+		Where("id = ? AND robot_id = ? AND status = ?", id, robotID, models.CommandDelivered).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"result":       result,
+			"completed_at": time.Now(),
+		})
+	if update.Error != nil {
+		return false, update.Error
+	}
+	return update.RowsAffected == 1, nil
+}
+
+// ExpirePending marca como failed os comandos na fila ou sem confirmação cujo TTL venceu
+func (r *robotCommandRepository) ExpirePending(now time.Time) (int64, error) {
+	result := r.db.Model(&models.RobotCommand{}).
+		Where("status IN ? AND expires_at <= ?", []models.CommandStatus{models.CommandQueued, models.CommandDelivered}, now).
+		Updates(map[string]interface{}{
+			"status":       models.CommandFailed,
+			"result":       "expired",
+			"completed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
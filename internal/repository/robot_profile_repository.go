@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RobotProfileRepository interface {
+	FindByRobotID(robotID uuid.UUID) (*models.RobotProfile, error)
+	Save(profile *models.RobotProfile) error
+	DeleteByRobotID(robotID uuid.UUID) error
+}
+
+type robotProfileRepository struct {
+	db *gorm.DB
+}
+
+func NewRobotProfileRepository(db *gorm.DB) RobotProfileRepository {
+	return &robotProfileRepository{db: db}
+}
+
+func (r *robotProfileRepository) FindByRobotID(robotID uuid.UUID) (*models.RobotProfile, error) {
+	var profile models.RobotProfile
+	if err := r.db.Where("robot_id = ?", robotID).First(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Save cria o perfil na primeira personalização e atualiza nas seguintes
+func (r *robotProfileRepository) Save(profile *models.RobotProfile) error {
+	if profile.ID == uuid.Nil {
+		return r.db.Omit(clause.Associations).Create(profile).Error
+	}
+	return r.db.Omit(clause.Associations).Save(profile).Error
+}
+
+func (r *robotProfileRepository) DeleteByRobotID(robotID uuid.UUID) error {
+	return r.db.Where("robot_id = ?", robotID).Delete(&models.RobotProfile{}).Error
+}
@@ -7,8 +7,12 @@ import (
 	"github.com/peruccii/roadmap-go-backend/internal/dtos"
 	"github.com/peruccii/roadmap-go-backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrPaymentAlreadyLinked indica que outro processamento já criou o robô do pagamento
+var ErrPaymentAlreadyLinked = errors.New("payment is already linked to a robot")
+
 type robotRepository struct{ db *gorm.DB }
 
 func NewRobotRepository(db *gorm.DB) RobotRepository {
@@ -21,6 +25,10 @@ type RobotRepository interface {
 	FindByIDAndUserID(id, userID string) (*models.Robot, error)
 	FindAll() ([]models.Robot, error)
 	FindById(id uuid.UUID) (*models.Robot, error)
+	Update(robot *models.Robot) error
+	UpdateBilling(robot *models.Robot) error
+	UpdateSettings(robot *models.Robot) error
+	CreateForPayment(robot *models.Robot, paymentID uuid.UUID) error
 }
 
 func (r *robotRepository) FindAll() ([]models.Robot, error) {
@@ -85,3 +93,45 @@ func (r *robotRepository) Create(robot *models.Robot) error {
 
 	return tx.Commit().Error
 }
+
+func (r *robotRepository) Update(robot *models.Robot) error {
+	return r.db.Omit(clause.Associations).Save(robot).Error
+}
+
+// UpdateBilling grava só os campos controlados por pagamento e assinatura. O
+// heartbeat e a conversa escrevem presença e firmware na mesma linha ao mesmo
+// tempo, e um Save da linha inteira desfaria essas escritas.
+func (r *robotRepository) UpdateBilling(robot *models.Robot) error {
+	return r.db.Model(&models.Robot{}).Where("id = ?", robot.ID).Updates(map[string]interface{}{
+		"status":           robot.Status,
+		"suspended_reason": robot.SuspendedReason,
+		"plan_valid_until": robot.PlanValidUntil,
+	}).Error
+}
+
+// CreateForPayment cria o robô comprado e grava o vínculo no pagamento na mesma
+// transação, para que um reenvio do evento do Stripe não crie um segundo robô.
+// O vínculo é condicional: se o pagamento já tem robô, nada é criado.
+func (r *robotRepository) CreateForPayment(robot *models.Robot, paymentID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(robot).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.Payment{}).Where("id = ? AND robot_id IS NULL", paymentID).Update("robot_id", robot.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPaymentAlreadyLinked
+		}
+		return nil
+	})
+}
+
+// UpdateSettings grava só as configurações escolhidas pelo dono
+func (r *robotRepository) UpdateSettings(robot *models.Robot) error {
+	return r.db.Model(&models.Robot{}).Where("id = ?", robot.ID).Updates(map[string]interface{}{
+		"display_url":    robot.DisplayURL,
+		"hardware_model": robot.HardwareModel,
+	}).Error
+}
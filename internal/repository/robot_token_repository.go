@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type RobotTokenRepository interface {
+	CreateSuperseding(token *models.RobotToken) error
+	FindByID(id uuid.UUID) (*models.RobotToken, error)
+	FindByRobotID(robotID uuid.UUID, limit int) ([]models.RobotToken, error)
+	CountByRobotID(robotID uuid.UUID) (int64, error)
+	Revoke(id uuid.UUID, reason string) (bool, error)
+	TouchLastUsed(id uuid.UUID, at time.Time) error
+}
+
+type robotTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRobotTokenRepository(db *gorm.DB) RobotTokenRepository {
+	return &robotTokenRepository{db: db}
+}
+
+// CreateSuperseding grava o novo token e revoga, na mesma transação, os tokens
+// ainda válidos do robô
+func (r *robotTokenRepository) CreateSuperseding(token *models.RobotToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.RobotToken{}).
+			Where("robot_id = ? AND revoked_at IS NULL", token.RobotID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"revoked_reason": models.TokenSuperseded,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *robotTokenRepository) FindByID(id uuid.UUID) (*models.RobotToken, error) {
+	var token models.RobotToken
+	if err := r.db.Where("id = ?", id).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *robotTokenRepository) FindByRobotID(robotID uuid.UUID, limit int) ([]models.RobotToken, error) {
+	var tokens []models.RobotToken
+	err := r.db.Where("robot_id = ?", robotID).Order("created_at DESC").Limit(limit).Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *robotTokenRepository) CountByRobotID(robotID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RobotToken{}).Where("robot_id = ?", robotID).Count(&count).Error
+	return count, err
+}
+
+// Revoke revoga um token ainda válido; retorna false se ele já estava revogado
+func (r *robotTokenRepository) Revoke(id uuid.UUID, reason string) (bool, error) {
+	result := r.db.Model(&models.RobotToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *robotTokenRepository) TouchLastUsed(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.RobotToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
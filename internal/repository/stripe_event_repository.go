@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StripeEventRepository interface {
+	CreateIfNotExists(event *models.StripeEvent) error
+	FindByID(id string) (*models.StripeEvent, error)
+	FindAll(status models.StripeEventStatus) ([]models.StripeEvent, error)
+	Claim(id string, force bool, staleBefore time.Time) (bool, error)
+	MarkProcessed(id string) error
+	MarkFailed(id string, cause error) error
+}
+
+type stripeEventRepository struct {
+	db *gorm.DB
+}
+
+func NewStripeEventRepository(db *gorm.DB) StripeEventRepository {
+	return &stripeEventRepository{db: db}
+}
+
+// CreateIfNotExists registra o evento apenas na primeira entrega
+func (r *stripeEventRepository) CreateIfNotExists(event *models.StripeEvent) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event).Error
+}
+
+func (r *stripeEventRepository) FindByID(id string) (*models.StripeEvent, error) {
+	var event models.StripeEvent
+	if err := r.db.Where("id = ?", id).First(&event).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *stripeEventRepository) FindAll(status models.StripeEventStatus) ([]models.StripeEvent, error) {
+	var events []models.StripeEvent
+	query := r.db.Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Claim marca o evento como "processing" de forma atômica. Retorna false se
+// o evento já foi processado ou está sendo processado por outra entrega.
+// Um evento em "processing" reivindicado antes de staleBefore é considerado
+// abandonado (o processo caiu no meio) e pode ser reivindicado de novo.
+// Com force (replay manual) também eventos já processados são reivindicados;
+// um processamento com lease ainda válido nunca é.
+func (r *stripeEventRepository) Claim(id string, force bool, staleBefore time.Time) (bool, error) {
+	statuses := []models.StripeEventStatus{models.StripeEventPending, models.StripeEventFailed}
+	if force {
+		statuses = append(statuses, models.StripeEventProcessed)
+	}
+
+	query := r.db.Model(&models.StripeEvent{}).
+		Where("id = ?", id).
+		Where("status IN ? OR (status = ? AND (claimed_at IS NULL OR claimed_at < ?))",
+			statuses, models.StripeEventProcessing, staleBefore)
+
+	result := query.Updates(map[string]interface{}{
+		"status":     models.StripeEventProcessing,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"claimed_at": time.Now(),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *stripeEventRepository) MarkProcessed(id string) error {
+	return r.db.Model(&models.StripeEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.StripeEventProcessed,
+		"last_error":   "",
+		"processed_at": time.Now(),
+	}).Error
+}
+
+func (r *stripeEventRepository) MarkFailed(id string, cause error) error {
+	return r.db.Model(&models.StripeEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     models.StripeEventFailed,
+		"last_error": cause.Error(),
+	}).Error
+}
@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/peruccii/roadmap-go-backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SubscriptionRepository interface {
@@ -19,6 +21,7 @@ type SubscriptionRepository interface {
 	Update(subscription *models.Subscription) error
 	FindExpiringSubscriptions(days int) ([]models.Subscription, error)
 	CancelSubscription(id uuid.UUID, cancelAtPeriodEnd bool) error
+	FindGracePeriodExpired(now time.Time) ([]models.Subscription, error)
 }
 
 type subscriptionRepository struct {
@@ -68,6 +71,9 @@ func (r *subscriptionRepository) FindByProviderSubscriptionID(providerSubscripti
 	err := r.db.Preload("User").Preload("Robot").Preload("Payments").
 		First(&subscription, "provider_subscription_id = ?", providerSubscriptionID).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &subscription, nil
@@ -77,8 +83,8 @@ func (r *subscriptionRepository) FindActiveByRobotID(robotID uuid.UUID) (*models
 	var subscription models.Subscription
 	now := time.Now()
 	err := r.db.Preload("User").Preload("Robot").Preload("Payments").
-		Where("robot_id = ? AND status = ? AND current_period_start <= ? AND current_period_end > ?", 
-			robotID, models.SubscriptionActive, now, now).
+		Where("robot_id = ? AND ((status = ? AND current_period_start <= ? AND current_period_end > ?) OR (status = ? AND grace_period_ends_at > ?))",
+			robotID, models.SubscriptionActive, now, now, models.SubscriptionPastDue, now).
 		First(&subscription).Error
 	if err != nil {
 		return nil, err
@@ -99,7 +105,7 @@ func (r *subscriptionRepository) UpdateStatus(id uuid.UUID, status models.Subscr
 }
 
 func (r *subscriptionRepository) Update(subscription *models.Subscription) error {
-	return r.db.Save(subscription).Error
+	return r.db.Omit(clause.Associations).Save(subscription).Error
 }
 
 func (r *subscriptionRepository) FindExpiringSubscriptions(days int) ([]models.Subscription, error) {
@@ -126,3 +132,11 @@ func (r *subscriptionRepository) CancelSubscription(id uuid.UUID, cancelAtPeriod
 	
 	return r.db.Model(&models.Subscription{}).Where("id = ?", id).Updates(updates).Error
 }
+
+// FindGracePeriodExpired busca assinaturas inadimplentes cujo período de graça já acabou
+func (r *subscriptionRepository) FindGracePeriodExpired(now time.Time) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	err := r.db.Where("status = ? AND grace_period_ends_at <= ?", models.SubscriptionPastDue, now).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
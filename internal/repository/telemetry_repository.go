@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// TelemetryBucket é a agregação dos pontos de uma métrica num intervalo
+type TelemetryBucket struct {
+	Bucket int64 // início do intervalo, em milissegundos Unix
+	Avg    float64
+	Min    float64
+	Max    float64
+	Count  int64
+}
+
+type TelemetryRepository interface {
+	CreateBatch(points []models.TelemetryPoint) error
+	Aggregate(robotID uuid.UUID, metric string, from, to, bucketMillis int64) ([]TelemetryBucket, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type telemetryRepository struct {
+	db *gorm.DB
+}
+
+func NewTelemetryRepository(db *gorm.DB) TelemetryRepository {
+	return &telemetryRepository{db: db}
+}
+
+func (r *telemetryRepository) CreateBatch(points []models.TelemetryPoint) error {
+	return r.db.CreateInBatches(points, 100).Error
+}
+
+// Aggregate agrupa os pontos em [from, to) em intervalos de bucketMillis. A divisão
+// inteira funciona igual em SQLite e Postgres, sem funções de data do banco.
+func (r *telemetryRepository) Aggregate(robotID uuid.UUID, metric string, from, to, bucketMillis int64) ([]TelemetryBucket, error) {
+	var buckets []TelemetryBucket
+	err := r.db.Model(&models.TelemetryPoint{}).
+		Select("(recorded_at / ?) * ? AS bucket, AVG(value) AS avg, MIN(value) AS min, MAX(value) AS max, COUNT(*) AS count", bucketMillis, bucketMillis).
+		Where("robot_id = ? AND metric = ? AND recorded_at >= ? AND recorded_at < ?", robotID, metric, from, to).
+		Group("bucket").
+		Order("bucket").
+		Scan(&buckets).Error
+	return buckets, err
+}
+
+func (r *telemetryRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", now).Delete(&models.TelemetryPoint{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+const (
+	defaultContextMaxTurns    = 10
+	defaultContextTokenBudget = 1000
+	defaultSessionIdleMinutes = 30
+)
+
+var ErrRobotNotFound = errors.New("robot not found")
+
+type ConversaService interface {
+	CurrentSession(roboID uuid.UUID) (*models.ConversaSession, error)
+	StartNewSession(roboID uuid.UUID) (*models.ConversaSession, error)
+	StartNewSessionForOwner(robotID, userID string) (*models.ConversaSession, error)
+	History(session *models.ConversaSession) ([]LLMMessage, error)
+}
+
+type conversaService struct {
+	repo        repository.ConversaRepository
+	robotRepo   repository.RobotRepository
+	maxTurns    int
+	tokenBudget int
+	idleTimeout time.Duration
+}
+
+func NewConversaService(repo repository.ConversaRepository, robotRepo repository.RobotRepository) ConversaService {
+	return &conversaService{
+		repo:        repo,
+		robotRepo:   robotRepo,
+		maxTurns:    envInt("CONVERSA_CONTEXT_MAX_TURNS", defaultContextMaxTurns),
+		tokenBudget: envInt("CONVERSA_CONTEXT_TOKEN_BUDGET", defaultContextTokenBudget),
+		idleTimeout: time.Duration(envInt("CONVERSA_SESSION_IDLE_MINUTES", defaultSessionIdleMinutes)) * time.Minute,
+	}
+}
+
+// CurrentSession retorna a sessão em aberto do robô. Depois de muito tempo
+// sem mensagens o assunto provavelmente mudou, então uma nova sessão é iniciada.
+func (s *conversaService) CurrentSession(roboID uuid.UUID) (*models.ConversaSession, error) {
+	session, err := s.repo.FindCurrentSession(roboID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session == nil {
+		return s.StartNewSession(roboID)
+	}
+
+	lastActivity := session.CreatedAt
+	if session.LastMessageAt != nil {
+		lastActivity = *session.LastMessageAt
+	}
+	if s.idleTimeout > 0 && time.Since(lastActivity) > s.idleTimeout {
+		return s.StartNewSession(roboID)
+	}
+
+	return session, nil
+}
+
+// StartNewSession encerra a sessão atual e abre outra com o contexto vazio
+func (s *conversaService) StartNewSession(roboID uuid.UUID) (*models.ConversaSession, error) {
+	if err := s.repo.EndSessions(roboID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	session := &models.ConversaSession{RoboID: roboID}
+	if err := s.repo.CreateSession(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// StartNewSessionForOwner permite ao dono reiniciar a conversa do próprio robô
+func (s *conversaService) StartNewSessionForOwner(robotID, userID string) (*models.ConversaSession, error) {
+	robot, err := s.robotRepo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+	return s.StartNewSession(robot.ID)
+}
+
+// History monta as trocas anteriores da sessão (mais antigas primeiro),
+// limitadas pelo número de turnos e por um orçamento aproximado de tokens
+func (s *conversaService) History(session *models.ConversaSession) ([]LLMMessage, error) {
+	if s.maxTurns <= 0 {
+		return nil, nil
+	}
+
+	logs, err := s.repo.FindRecentLogs(session.ID, s.maxTurns)
+	if err != nil {
+		return nil, err
+	}
+
+	// Percorre do mais recente para o mais antigo até estourar o orçamento
+	budget := s.tokenBudget
+	kept := 0
+	for _, log := range logs {
+		cost := estimateTokens(log.Pergunta) + estimateTokens(log.Resposta)
+		if s.tokenBudget > 0 && cost > budget {
+			break
+		}
+		budget -= cost
+		kept++
+	}
+
+	// As respostas voltam no mesmo formato JSON exigido pelo prompt de sistema
+	history := make([]LLMMessage, 0, kept*2)
+	for i := kept - 1; i >= 0; i-- {
+		answer, err := json.Marshal(iaResponseFormat{Resposta: logs[i].Resposta, Emocao: logs[i].Emocao})
+		if err != nil {
+			return nil, err
+		}
+		history = append(history,
+			LLMMessage{Role: LLMRoleUser, Content: logs[i].Pergunta},
+			LLMMessage{Role: LLMRoleAssistant, Content: string(answer)},
+		)
+	}
+	return history, nil
+}
+
+// estimateTokens aproxima a contagem de tokens (~4 caracteres por token)
+func estimateTokens(text string) int {
+	return utf8.RuneCountInString(text)/4 + 1
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+var ErrModelPriceNotFound = errors.New("model price not found")
+
+// CostService calcula o custo em USD das chamadas de IA pela tabela de preços por modelo
+type CostService interface {
+	SeedDefaults() error
+	FindAll() ([]models.ModelPrice, error)
+	SetPrice(model string, input dtos.ModelPriceInputDTO) (*models.ModelPrice, error)
+	DeletePrice(model string) error
+	Cost(model string, promptTokens, completionTokens int) (float64, error)
+}
+
+type costService struct {
+	repo repository.ModelPriceRepository
+}
+
+func NewCostService(repo repository.ModelPriceRepository) CostService {
+	return &costService{repo: repo}
+}
+
+// SeedDefaults cria os preços dos modelos usados por padrão quando a tabela está vazia
+func (s *costService) SeedDefaults() error {
+	count, err := s.repo.Count()
+	if err != nil || count > 0 {
+		return err
+	}
+
+	defaults := []models.ModelPrice{
+		{Model: "gpt-3.5-turbo", PromptPerMillion: 0.50, CompletionPerMillion: 1.50},
+		{Model: "gpt-4o-mini", PromptPerMillion: 0.15, CompletionPerMillion: 0.60},
+		{Model: "gpt-4o", PromptPerMillion: 2.50, CompletionPerMillion: 10.00},
+		// Modelos locais e o provider fake não têm custo por token
+		{Model: "llama3", PromptPerMillion: 0, CompletionPerMillion: 0},
+		{Model: "fake", PromptPerMillion: 0, CompletionPerMillion: 0},
+	}
+
+	for i := range defaults {
+		defaults[i].Currency = "USD"
+		if err := s.repo.Upsert(&defaults[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *costService) FindAll() ([]models.ModelPrice, error) {
+	return s.repo.FindAll()
+}
+
+func (s *costService) SetPrice(model string, input dtos.ModelPriceInputDTO) (*models.ModelPrice, error) {
+	if err := s.repo.Upsert(&models.ModelPrice{
+		Model:                model,
+		PromptPerMillion:     input.PromptPerMillion,
+		CompletionPerMillion: input.CompletionPerMillion,
+		Currency:             "USD",
+	}); err != nil {
+		return nil, err
+	}
+
+	// O upsert mantém o ID original em caso de conflito, então relê o registro
+	return s.repo.FindByModel(model)
+}
+
+func (s *costService) DeletePrice(model string) error {
+	deleted, err := s.repo.Delete(model)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrModelPriceNotFound
+	}
+	return nil
+}
+
+// Cost usa o preço do modelo exato ou, na falta dele, o do prefixo mais longo
+// ("gpt-4o-mini-2024-07-18" usa "gpt-4o-mini"). Modelo sem preço custa zero.
+func (s *costService) Cost(model string, promptTokens, completionTokens int) (float64, error) {
+	prices, err := s.repo.FindAll()
+	if err != nil {
+		return 0, err
+	}
+
+	var match *models.ModelPrice
+	for i := range prices {
+		price := &prices[i]
+		if price.Model == model {
+			match = price
+			break
+		}
+		if strings.HasPrefix(model, price.Model) && (match == nil || len(price.Model) > len(match.Model)) {
+			match = price
+		}
+	}
+
+	if match == nil {
+		log.Printf("Custo de IA: nenhum preço configurado para o modelo %q\n", model)
+		return 0, nil
+	}
+
+	return (float64(promptTokens)*match.PromptPerMillion + float64(completionTokens)*match.CompletionPerMillion) / 1_000_000, nil
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+// Erros do endpoint de token com os códigos da RFC 8628, devolvidos ao robô como estão
+var (
+	ErrAuthorizationPending = errors.New("authorization_pending")
+	ErrPollingTooFast       = errors.New("slow_down")
+	ErrDeviceCodeExpired    = errors.New("expired_token")
+	ErrInvalidDeviceCode    = errors.New("invalid_grant")
+)
+
+var ErrInvalidUserCode = errors.New("invalid or expired user code")
+
+const (
+	defaultDeviceVerificationURI = "http://localhost:3000/pair"
+	deviceAuthorizationTTL       = 10 * time.Minute
+	deviceAuthorizationInterval  = 5 // segundos; cada slow_down soma mais 5
+	userCodeLength               = 8
+)
+
+// DeviceAuthorizationService implementa o pareamento por autorização de
+// dispositivo: o robô pede um código, o dono aprova no app e o robô consulta
+// até receber o token, com as mesmas claims de GenerateRobotToken
+type DeviceAuthorizationService interface {
+	Authorize(input dtos.DeviceAuthorizeInputDTO) (*dtos.DeviceAuthorizeResponseDTO, error)
+	Approve(robotID, userID string, input dtos.ApproveDevicePairingInputDTO) (*models.DeviceAuthorization, error)
+	Token(input dtos.DeviceTokenInputDTO) (*dtos.DeviceTokenResponseDTO, error)
+	PurgeExpired() (int64, error)
+	RunPurgeWorker(ctx context.Context, interval time.Duration)
+}
+
+type deviceAuthorizationService struct {
+	repo            repository.DeviceAuthorizationRepository
+	robotRepo       repository.RobotRepository
+	robotService    RobotService
+	authorizer      RobotAuthorizer
+	verificationURI string
+}
+
+func NewDeviceAuthorizationService(repo repository.DeviceAuthorizationRepository, robotRepo repository.RobotRepository, robotService RobotService, authorizer RobotAuthorizer) DeviceAuthorizationService {
+	verificationURI := os.Getenv("DEVICE_VERIFICATION_URI")
+	if verificationURI == "" {
+		verificationURI = defaultDeviceVerificationURI
+	}
+	return &deviceAuthorizationService{
+		repo:            repo,
+		robotRepo:       robotRepo,
+		robotService:    robotService,
+		authorizer:      authorizer,
+		verificationURI: verificationURI,
+	}
+}
+
+func (s *deviceAuthorizationService) Authorize(input dtos.DeviceAuthorizeInputDTO) (*dtos.DeviceAuthorizeResponseDTO, error) {
+	deviceCode, err := randomDeviceSecret()
+	if err != nil {
+		return nil, err
+	}
+	userCode, err := randomCode(userCodeLength)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization := &models.DeviceAuthorization{
+		DeviceCodeHash: hashDeviceCredential(deviceCode),
+		UserCode:       userCode,
+		Status:         models.DeviceAuthorizationPending,
+		Serial:         input.Serial,
+		Interval:       deviceAuthorizationInterval,
+		ExpiresAt:      time.Now().Add(deviceAuthorizationTTL),
+	}
+	if err := s.repo.Create(authorization); err != nil {
+		return nil, err
+	}
+
+	return &dtos.DeviceAuthorizeResponseDTO{
+		DeviceCode:      deviceCode,
+		UserCode:        formatClaimCode(userCode),
+		VerificationURI: s.verificationURI,
+		ExpiresIn:       int(deviceAuthorizationTTL.Seconds()),
+		Interval:        deviceAuthorizationInterval,
+	}, nil
+}
+
+// Approve pareia o pedido do user_code com um robô do usuário
+func (s *deviceAuthorizationService) Approve(robotID, userID string, input dtos.ApproveDevicePairingInputDTO) (*models.DeviceAuthorization, error) {
+	robot, err := s.robotRepo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+
+	now := time.Now()
+	authorization, err := s.repo.FindPendingByUserCode(normalizeClaimCode(input.UserCode), now)
+	if err != nil {
+		return nil, err
+	}
+	if authorization == nil {
+		return nil, ErrInvalidUserCode
+	}
+
+	ok, err := s.repo.Approve(authorization.ID, robot.ID, robot.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidUserCode
+	}
+
+	authorization.Status = models.DeviceAuthorizationApproved
+	authorization.RobotID = &robot.ID
+	authorization.ApprovedBy = &robot.UserID
+	authorization.ApprovedAt = &now
+	return authorization, nil
+}
+
+// Token é consultado pelo robô a cada Interval segundos até o dono aprovar.
+// O token sai uma única vez e passa pelas regras do RoboAuthMiddleware antes.
+func (s *deviceAuthorizationService) Token(input dtos.DeviceTokenInputDTO) (*dtos.DeviceTokenResponseDTO, error) {
+	authorization, err := s.repo.FindByDeviceCodeHash(hashDeviceCredential(input.DeviceCode))
+	if err != nil {
+		return nil, err
+	}
+	if authorization == nil || authorization.Status == models.DeviceAuthorizationConsumed {
+		return nil, ErrInvalidDeviceCode
+	}
+
+	now := time.Now()
+	if !now.Before(authorization.ExpiresAt) {
+		return nil, ErrDeviceCodeExpired
+	}
+
+	interval := authorization.Interval
+	tooFast := authorization.LastPolledAt != nil && now.Sub(*authorization.LastPolledAt) < time.Duration(interval)*time.Second
+	if tooFast {
+		interval += deviceAuthorizationInterval
+	}
+	if err := s.repo.RecordPoll(authorization.ID, now, interval); err != nil {
+		return nil, err
+	}
+	if tooFast {
+		return nil, ErrPollingTooFast
+	}
+	if authorization.Status != models.DeviceAuthorizationApproved || authorization.RobotID == nil {
+		return nil, ErrAuthorizationPending
+	}
+
+	// Cada token novo revoga o anterior do robô, então nada é emitido antes de o
+	// robô passar nas regras e de esta consulta ficar, sozinha, com o device_code
+	if _, err := s.authorizer.AuthorizeRobot(*authorization.RobotID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeviceRobotUnavailable, err)
+	}
+	ok, err := s.repo.Consume(authorization.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Outra consulta com o mesmo device_code levou o token
+		return nil, ErrInvalidDeviceCode
+	}
+
+	token, err := s.robotService.IssueToken(*authorization.RobotID, models.TokenFromPairing)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.DeviceTokenResponseDTO{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		RobotID:     authorization.RobotID.String(),
+	}, nil
+}
+
+// PurgeExpired apaga os pedidos vencidos há mais de um dia
+func (s *deviceAuthorizationService) PurgeExpired() (int64, error) {
+	return s.repo.DeleteExpired(time.Now().Add(-24 * time.Hour))
+}
+
+// RunPurgeWorker executa PurgeExpired periodicamente até o contexto ser cancelado
+func (s *deviceAuthorizationService) RunPurgeWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PurgeExpired(); err != nil {
+			log.Printf("Erro ao apagar pedidos de pareamento vencidos: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
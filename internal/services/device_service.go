@@ -0,0 +1,313 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+var (
+	ErrDeviceSerialExists     = errors.New("device serial already registered")
+	ErrDuplicateDeviceSerial  = errors.New("device serial repeated in the batch")
+	ErrInvalidClaimCode       = errors.New("invalid serial or claim code")
+	ErrDeviceClaimLocked      = errors.New("too many invalid claim codes for this device; contact support")
+	ErrDeviceAlreadyClaimed   = errors.New("device is already linked to a robot")
+	ErrRobotHasDevice         = errors.New("robot already has a linked device; release it first")
+	ErrDeviceNotFound         = errors.New("no device linked to this robot")
+	ErrUnknownDeviceSerial    = errors.New("device serial not found")
+	ErrInvalidDeviceSecret    = errors.New("invalid serial or device secret")
+	ErrDeviceNotClaimed       = errors.New("device has not been claimed by an owner yet")
+	ErrDeviceRobotUnavailable = errors.New("robot linked to the device cannot use the API")
+)
+
+const (
+	// Códigos errados seguidos até o vínculo do dispositivo ser bloqueado
+	maxDeviceClaimAttempts = 10
+	// Sem 0/O e 1/I para não confundir quem digita o código da caixa
+	claimCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	claimCodeLength   = 12
+)
+
+// DeviceService cuida do ciclo do dispositivo físico: importação pela fábrica,
+// vínculo pelo dono com o código da caixa e troca do segredo do dispositivo por
+// um token do robô
+type DeviceService interface {
+	Import(input dtos.ImportDevicesInputDTO) ([]dtos.ImportedDeviceDTO, error)
+	FindAll(status models.DeviceStatus) ([]models.Device, error)
+	UnlockClaim(serial string) (*models.Device, error)
+	Claim(robotID, userID string, input dtos.ClaimDeviceInputDTO) (*models.Device, error)
+	FindForOwner(robotID, userID string) (*models.Device, error)
+	Release(robotID, userID string) error
+	Bootstrap(input dtos.DeviceBootstrapInputDTO) (*dtos.DeviceBootstrapResponseDTO, error)
+}
+
+type deviceService struct {
+	repo         repository.DeviceRepository
+	robotRepo    repository.RobotRepository
+	robotService RobotService
+	authorizer   RobotAuthorizer
+}
+
+func NewDeviceService(repo repository.DeviceRepository, robotRepo repository.RobotRepository, robotService RobotService, authorizer RobotAuthorizer) DeviceService {
+	return &deviceService{
+		repo:         repo,
+		robotRepo:    robotRepo,
+		robotService: robotService,
+		authorizer:   authorizer,
+	}
+}
+
+// Import cadastra o lote inteiro ou nada
+func (s *deviceService) Import(input dtos.ImportDevicesInputDTO) ([]dtos.ImportedDeviceDTO, error) {
+	serials := make([]string, 0, len(input.Devices))
+	seen := make(map[string]bool, len(input.Devices))
+	for _, item := range input.Devices {
+		serial := strings.TrimSpace(item.Serial)
+		if seen[serial] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateDeviceSerial, serial)
+		}
+		seen[serial] = true
+		serials = append(serials, serial)
+	}
+
+	existing, err := s.repo.FindExistingSerials(serials)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDeviceSerialExists, strings.Join(existing, ", "))
+	}
+
+	devices := make([]models.Device, len(input.Devices))
+	imported := make([]dtos.ImportedDeviceDTO, len(input.Devices))
+	for i, item := range input.Devices {
+		claimCode := normalizeClaimCode(item.ClaimCode)
+		if claimCode == "" {
+			if claimCode, err = randomCode(claimCodeLength); err != nil {
+				return nil, err
+			}
+		}
+		secret := item.DeviceSecret
+		if secret == "" {
+			if secret, err = randomDeviceSecret(); err != nil {
+				return nil, err
+			}
+		}
+
+		devices[i] = models.Device{
+			Serial:        serials[i],
+			HardwareModel: strings.TrimSpace(item.HardwareModel),
+			ClaimCodeHash: hashDeviceCredential(claimCode),
+			SecretHash:    hashDeviceCredential(secret),
+			Status:        models.DeviceUnclaimed,
+		}
+		imported[i] = dtos.ImportedDeviceDTO{
+			Serial:        serials[i],
+			HardwareModel: devices[i].HardwareModel,
+			ClaimCode:     formatClaimCode(claimCode),
+			DeviceSecret:  secret,
+		}
+	}
+
+	if err := s.repo.CreateBatch(devices); err != nil {
+		return nil, err
+	}
+	for i := range devices {
+		imported[i].ID = devices[i].ID.String()
+	}
+	return imported, nil
+}
+
+// FindAll lista os últimos 500 dispositivos cadastrados
+func (s *deviceService) FindAll(status models.DeviceStatus) ([]models.Device, error) {
+	return s.repo.FindAll(status, 500)
+}
+
+// UnlockClaim zera os códigos errados de um dispositivo bloqueado (atendimento ao dono)
+func (s *deviceService) UnlockClaim(serial string) (*models.Device, error) {
+	device, err := s.repo.FindBySerial(serial)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrUnknownDeviceSerial
+	}
+	if err := s.repo.ResetClaimAttempts(device.ID); err != nil {
+		return nil, err
+	}
+	device.ClaimAttempts = 0
+	return device, nil
+}
+
+// Claim vincula um dispositivo ainda livre ao robô do usuário. Serial ou código
+// errados têm a mesma resposta, e códigos errados seguidos bloqueiam o dispositivo.
+func (s *deviceService) Claim(robotID, userID string, input dtos.ClaimDeviceInputDTO) (*models.Device, error) {
+	robot, err := s.robotRepo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+
+	current, err := s.repo.FindByRobotID(robot.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		return nil, ErrRobotHasDevice
+	}
+
+	device, err := s.repo.FindBySerial(strings.TrimSpace(input.Serial))
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrInvalidClaimCode
+	}
+	if device.ClaimAttempts >= maxDeviceClaimAttempts {
+		return nil, ErrDeviceClaimLocked
+	}
+	if !matchesDeviceCredential(device.ClaimCodeHash, normalizeClaimCode(input.ClaimCode)) {
+		if err := s.repo.RecordFailedClaim(device.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidClaimCode
+	}
+	if device.Status != models.DeviceUnclaimed {
+		return nil, ErrDeviceAlreadyClaimed
+	}
+
+	ok, err := s.repo.Claim(device.ID, robot.ID, robot.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrDeviceAlreadyClaimed
+	}
+
+	// O modelo de hardware do dispositivo define as animações das emoções do robô
+	if robot.HardwareModel == "" && device.HardwareModel != "" {
+		robot.HardwareModel = device.HardwareModel
+		if err := s.robotRepo.UpdateSettings(robot); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.FindByRobotID(robot.ID)
+}
+
+func (s *deviceService) FindForOwner(robotID, userID string) (*models.Device, error) {
+	robot, err := s.robotRepo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+
+	device, err := s.repo.FindByRobotID(robot.ID)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrDeviceNotFound
+	}
+	return device, nil
+}
+
+// Release desvincula o dispositivo do robô (ex.: troca de aparelho ou revenda) e
+// revoga os tokens do robô; o novo aparelho recebe outro pelo bootstrap
+func (s *deviceService) Release(robotID, userID string) error {
+	device, err := s.FindForOwner(robotID, userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Release(device.ID, *device.RobotID)
+}
+
+// Bootstrap troca o segredo do dispositivo por um token do robô vinculado. O
+// robô passa pelas mesmas regras do RoboAuthMiddleware antes de o token ser emitido.
+func (s *deviceService) Bootstrap(input dtos.DeviceBootstrapInputDTO) (*dtos.DeviceBootstrapResponseDTO, error) {
+	device, err := s.repo.FindBySerial(strings.TrimSpace(input.Serial))
+	if err != nil {
+		return nil, err
+	}
+	if device == nil || !matchesDeviceCredential(device.SecretHash, input.DeviceSecret) {
+		return nil, ErrInvalidDeviceSecret
+	}
+	if device.Status != models.DeviceClaimed || device.RobotID == nil {
+		return nil, ErrDeviceNotClaimed
+	}
+
+	// Checa antes de emitir: o novo token revoga o atual do robô
+	if _, err := s.authorizer.AuthorizeRobot(*device.RobotID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeviceRobotUnavailable, err)
+	}
+	token, err := s.robotService.IssueToken(*device.RobotID, models.TokenFromBootstrap)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RecordBootstrap(device.ID, time.Now()); err != nil {
+		return nil, err
+	}
+	return &dtos.DeviceBootstrapResponseDTO{RobotID: device.RobotID.String(), Token: token}, nil
+}
+
+// normalizeClaimCode aceita o código com ou sem hífens, espaços e em minúsculas
+func normalizeClaimCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// formatClaimCode agrupa o código de 4 em 4 caracteres para impressão (XXXX-XXXX-XXXX)
+func formatClaimCode(code string) string {
+	var groups []string
+	for len(code) > 4 {
+		groups = append(groups, code[:4])
+		code = code[4:]
+	}
+	return strings.Join(append(groups, code), "-")
+}
+
+// randomCode gera um código legível com o alfabeto dos códigos de vinculação
+func randomCode(length int) (string, error) {
+	buf := make([]byte, length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	// 256 é múltiplo do tamanho do alfabeto (32), então o módulo não tem viés
+	for i, b := range buf {
+		buf[i] = claimCodeAlphabet[int(b)%len(claimCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+func randomDeviceSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Os segredos são aleatórios e longos, então um SHA-256 simples basta (ao
+// contrário das senhas, que usam bcrypt)
+func hashDeviceCredential(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func matchesDeviceCredential(hash, value string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(hashDeviceCredential(value))) == 1
+}
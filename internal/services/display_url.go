@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrDisplayAddressBlocked indica que o display_url de um robô aponta para a rede interna
+var ErrDisplayAddressBlocked = errors.New("display_url resolves to a private, loopback or link-local address")
+
+// O display_url é escolhido pelo dono do robô, mas quem faz o POST é o servidor.
+// Sem estas checagens qualquer dono poderia apontá-lo para a rede interna
+// (metadados da nuvem em 169.254.169.254, serviços em localhost...).
+
+// carrierGradeNAT (100.64.0.0/10) também não é roteável pela internet
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIP indica se o endereço pode receber requisições do servidor
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || carrierGradeNAT.Contains(ip))
+}
+
+// validateDisplayURL aceita só URLs http(s) cujo host não seja interno. Nomes que
+// não resolvem agora são aceitos; o dial do outbox checa de novo a cada envio.
+func validateDisplayURL(raw string) error {
+	parsed, err := url.ParseRequestURI(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return ErrInvalidDisplayURL
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrInvalidDisplayURL
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return ErrInvalidDisplayURL
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return ErrInvalidDisplayURL
+		}
+	}
+	return nil
+}
+
+// newDisplayHTTPClient cria o cliente usado nos display_url dos robôs. A checagem
+// roda no Control do dialer, já com o IP resolvido, então vale também para
+// redirecionamentos e para nomes que mudam de IP depois de salvos (DNS rebinding).
+func newDisplayHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrDisplayAddressBlocked, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // um proxy faria a conexão no lugar do dialer checado
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
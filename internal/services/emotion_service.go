@@ -0,0 +1,280 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+var (
+	ErrEmotionNotFound         = errors.New("emotion not found")
+	ErrEmotionAlreadyExists    = errors.New("emotion already exists")
+	ErrDefaultEmotionRequired  = errors.New("the catalog must keep a default emotion; mark another emotion as default first")
+	ErrEmotionAnimationMissing = errors.New("emotion animation not found")
+)
+
+// ResolvedEmotion é a emoção normalizada com a animação do modelo do robô
+type ResolvedEmotion struct {
+	ID        string
+	Animation *models.EmotionAnimation // nil quando não há mapeamento
+}
+
+type EmotionService interface {
+	SeedDefaults() error
+	FindAll() ([]models.Emotion, error)
+	KnownIDs() ([]string, error)
+	Create(input dtos.CreateEmotionInputDTO) (*models.Emotion, error)
+	Update(id string, input dtos.UpdateEmotionInputDTO) (*models.Emotion, error)
+	Delete(id string) error
+	SetAnimation(emotionID, hardwareModel string, input dtos.EmotionAnimationInputDTO) (*models.EmotionAnimation, error)
+	DeleteAnimation(emotionID, hardwareModel string) error
+	Resolve(raw string, allowed []string, hardwareModel string) (*ResolvedEmotion, error)
+}
+
+type emotionService struct {
+	repo repository.EmotionRepository
+}
+
+func NewEmotionService(repo repository.EmotionRepository) EmotionService {
+	return &emotionService{repo: repo}
+}
+
+// SeedDefaults cria as emoções que o prompt sempre usou, com animações
+// genéricas para o modelo "default", quando o catálogo está vazio
+func (s *emotionService) SeedDefaults() error {
+	count, err := s.repo.Count()
+	if err != nil || count > 0 {
+		return err
+	}
+
+	defaults := []struct {
+		emotion   models.Emotion
+		animation string
+		led       string
+	}{
+		{models.Emotion{ID: "feliz", Name: "Feliz", Aliases: []string{"alegre", "contente", "happy"}}, "smile", "#FFD700"},
+		{models.Emotion{ID: "triste", Name: "Triste", Aliases: []string{"chateado", "sad"}}, "sad_eyes", "#1E90FF"},
+		{models.Emotion{ID: "animado", Name: "Animado", Aliases: []string{"empolgado", "excited"}}, "bounce", "#FF8C00"},
+		{models.Emotion{ID: "pensativo", Name: "Pensativo", Aliases: []string{"reflexivo", "curioso", "thinking"}}, "look_up", "#9370DB"},
+		{models.Emotion{ID: "confuso", Name: "Confuso", Aliases: []string{"perdido", "confused"}}, "head_tilt", "#FF69B4"},
+		{models.Emotion{ID: "neutro", Name: "Neutro", Aliases: []string{"normal", "calmo", "neutral"}, IsDefault: true}, "idle", "#FFFFFF"},
+	}
+
+	for _, item := range defaults {
+		emotion := item.emotion
+		if err := s.repo.Create(&emotion); err != nil {
+			return err
+		}
+		if err := s.repo.SaveAnimation(&models.EmotionAnimation{
+			EmotionID:     emotion.ID,
+			HardwareModel: models.DefaultHardwareModel,
+			AnimationCode: item.animation,
+			LEDCode:       item.led,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *emotionService) FindAll() ([]models.Emotion, error) {
+	return s.repo.FindAll()
+}
+
+func (s *emotionService) KnownIDs() ([]string, error) {
+	emotions, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(emotions))
+	for i, emotion := range emotions {
+		ids[i] = emotion.ID
+	}
+	return ids, nil
+}
+
+func (s *emotionService) Create(input dtos.CreateEmotionInputDTO) (*models.Emotion, error) {
+	id := normalizeEmotion(input.ID)
+	existing, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmotionAlreadyExists
+	}
+
+	if input.IsDefault {
+		if err := s.repo.ClearDefault(); err != nil {
+			return nil, err
+		}
+	}
+
+	emotion := &models.Emotion{
+		ID:        id,
+		Name:      input.Name,
+		Aliases:   input.Aliases,
+		IsDefault: input.IsDefault,
+	}
+	if err := s.repo.Create(emotion); err != nil {
+		return nil, err
+	}
+	return emotion, nil
+}
+
+func (s *emotionService) Update(id string, input dtos.UpdateEmotionInputDTO) (*models.Emotion, error) {
+	emotion, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if emotion == nil {
+		return nil, ErrEmotionNotFound
+	}
+
+	if input.Name != nil {
+		emotion.Name = *input.Name
+	}
+	if input.Aliases != nil {
+		emotion.Aliases = input.Aliases
+	}
+	if input.IsDefault != nil && *input.IsDefault != emotion.IsDefault {
+		// A padrão só muda elegendo outra, nunca desmarcando
+		if !*input.IsDefault {
+			return nil, ErrDefaultEmotionRequired
+		}
+		if err := s.repo.ClearDefault(); err != nil {
+			return nil, err
+		}
+		emotion.IsDefault = true
+	}
+
+	if err := s.repo.Update(emotion); err != nil {
+		return nil, err
+	}
+	return emotion, nil
+}
+
+func (s *emotionService) Delete(id string) error {
+	emotion, err := s.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if emotion == nil {
+		return ErrEmotionNotFound
+	}
+	if emotion.IsDefault {
+		return ErrDefaultEmotionRequired
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *emotionService) SetAnimation(emotionID, hardwareModel string, input dtos.EmotionAnimationInputDTO) (*models.EmotionAnimation, error) {
+	emotion, err := s.repo.FindByID(emotionID)
+	if err != nil {
+		return nil, err
+	}
+	if emotion == nil {
+		return nil, ErrEmotionNotFound
+	}
+
+	if err := s.repo.SaveAnimation(&models.EmotionAnimation{
+		EmotionID:     emotion.ID,
+		HardwareModel: hardwareModel,
+		AnimationCode: input.AnimationCode,
+		LEDCode:       input.LEDCode,
+	}); err != nil {
+		return nil, err
+	}
+
+	// O upsert mantém o ID original em caso de conflito, então relê o registro
+	return s.repo.FindAnimation(emotion.ID, hardwareModel)
+}
+
+func (s *emotionService) DeleteAnimation(emotionID, hardwareModel string) error {
+	deleted, err := s.repo.DeleteAnimation(emotionID, hardwareModel)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrEmotionAnimationMissing
+	}
+	return nil
+}
+
+// Resolve normaliza a emoção devolvida pelo modelo contra o catálogo (ID ou alias).
+// Emoções desconhecidas ou fora das permitidas pela persona viram a emoção padrão.
+func (s *emotionService) Resolve(raw string, allowed []string, hardwareModel string) (*ResolvedEmotion, error) {
+	emotions, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	isAllowed := func(id string) bool {
+		return len(allowed) == 0 || slices.Contains(allowed, id)
+	}
+
+	resolved := ""
+	normalized := normalizeEmotion(raw)
+	for _, emotion := range emotions {
+		if !isAllowed(emotion.ID) {
+			continue
+		}
+		if normalizeEmotion(emotion.ID) == normalized || slices.ContainsFunc(emotion.Aliases, func(alias string) bool {
+			return normalizeEmotion(alias) == normalized
+		}) {
+			resolved = emotion.ID
+			break
+		}
+	}
+
+	if resolved == "" {
+		for _, emotion := range emotions {
+			if emotion.IsDefault {
+				resolved = emotion.ID
+			}
+		}
+		switch {
+		case !isAllowed(resolved):
+			resolved = allowed[0]
+		case resolved == "":
+			// Catálogo vazio: sem referência, mantém a saída do modelo
+			resolved = normalized
+		}
+	}
+
+	if hardwareModel == "" {
+		hardwareModel = models.DefaultHardwareModel
+	}
+	animation, err := s.repo.FindAnimation(resolved, hardwareModel)
+	if err != nil {
+		return nil, err
+	}
+	if animation == nil && hardwareModel != models.DefaultHardwareModel {
+		if animation, err = s.repo.FindAnimation(resolved, models.DefaultHardwareModel); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ResolvedEmotion{ID: resolved, Animation: animation}, nil
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u",
+	"ç", "c",
+)
+
+// normalizeEmotion ignora caixa, acentos, espaços e pontuação em volta
+func normalizeEmotion(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	value = strings.Trim(value, ` '".!`)
+	return accentReplacer.Replace(value)
+}
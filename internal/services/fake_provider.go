@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// fakeProvider responde de forma determinística, sem rede, para desenvolvimento e testes
+type fakeProvider struct {
+	config LLMProviderConfig
+}
+
+func NewFakeProvider(config LLMProviderConfig) LLMProvider {
+	return &fakeProvider{config: config}
+}
+
+func (p *fakeProvider) Name() string {
+	return LLMProviderFake
+}
+
+// Complete ecoa a última mensagem do usuário; perguntas recebem a emoção "pensativo"
+func (p *fakeProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var userMessage string
+	for _, message := range req.Messages {
+		if message.Role == LLMRoleUser {
+			userMessage = message.Content
+		}
+	}
+
+	resposta := "Você disse: " + strings.TrimSpace(userMessage)
+	if words := strings.Fields(resposta); p.config.MaxTokens > 0 && len(words) > p.config.MaxTokens {
+		resposta = strings.Join(words[:p.config.MaxTokens], " ")
+	}
+
+	emocao := "neutro"
+	if strings.HasSuffix(strings.TrimSpace(userMessage), "?") {
+		emocao = "pensativo"
+	}
+
+	content := resposta
+	if req.JSONMode {
+		payload, err := json.Marshal(iaResponseFormat{Resposta: resposta, Emocao: emocao})
+		if err != nil {
+			return nil, err
+		}
+		content = string(payload)
+	}
+
+	promptTokens, completionTokens := estimateUsage(req, content)
+	return &LLMResponse{
+		Content:          content,
+		Model:            p.config.Model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+	}, nil
+}
+
+// Stream entrega a mesma resposta de Complete em pedaços pequenos, simulando o streaming
+func (p *fakeProvider) Stream(ctx context.Context, req LLMRequest, onChunk LLMChunkHandler) (*LLMResponse, error) {
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	const chunkSize = 8
+	runes := []rune(resp.Content)
+	for start := 0; start < len(runes); start += chunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := min(start+chunkSize, len(runes))
+		if err := onChunk(string(runes[start:end])); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
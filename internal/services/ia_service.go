@@ -5,9 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
-
-	openai "github.com/sashabaranov/go-openai"
+	"strings"
+	"unicode/utf8"
 )
 
 type iaResponseFormat struct {
@@ -15,75 +16,159 @@ type iaResponseFormat struct {
 	Emocao   string `json:"emocao"`
 }
 
+// IARequest é uma fala do usuário com o contexto necessário para respondê-la
+type IARequest struct {
+	SystemPrompt string // ver BuildSystemPrompt
+	History      []LLMMessage
+	Texto        string
+}
+
+// IAResult é a fala gerada pela IA com o consumo de tokens da chamada
+type IAResult struct {
+	Resposta         string
+	Emocao           string // como veio do modelo, antes da normalização pelo catálogo
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	Fallback         bool // resposta padrão usada porque o provedor falhou
+}
+
+// IAFallback é a fala usada quando o provedor de IA não responde
+type IAFallback struct {
+	Resposta string
+	Emocao   string
+}
+
+// IAFallbackFromEnv lê LLM_FALLBACK_REPLY e LLM_FALLBACK_EMOTION
+func IAFallbackFromEnv() IAFallback {
+	fallback := IAFallback{
+		Resposta: "Desculpe, estou com dificuldade para pensar agora. Pode repetir daqui a pouco?",
+		Emocao:   "confuso",
+	}
+	if value := strings.TrimSpace(os.Getenv("LLM_FALLBACK_REPLY")); value != "" {
+		fallback.Resposta = value
+	}
+	if value := strings.TrimSpace(os.Getenv("LLM_FALLBACK_EMOTION")); value != "" {
+		fallback.Emocao = value
+	}
+	return fallback
+}
+
 type IAServiceInterface interface {
-	Generate(prompt string) (string, string, error)
+	Generate(ctx context.Context, req IARequest) (*IAResult, error)
+	// GenerateStream repassa a "resposta" em pedaços conforme o modelo gera o JSON
+	GenerateStream(ctx context.Context, req IARequest, onDelta func(delta string) error) (*IAResult, error)
 }
 
 type iaService struct {
-	client *openai.Client
+	provider LLMProvider
+	fallback IAFallback
 }
 
-func NewIAService() IAServiceInterface {
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		fmt.Println("Atenção: OPENAI_API_KEY não está definida.")
-	}
+func NewIAService(provider LLMProvider, fallback IAFallback) IAServiceInterface {
 	return &iaService{
-		client: openai.NewClient(apiKey),
-	}
-}
-
-func (s *iaService) Generate(prompt string) (string, string, error) {
-	systemPrompt := `
-        Você é a personalidade principal de um robô inteligente, descontraído e gente boa, criado pra conversar com humanos de forma leve, divertida e natural — estilo geração Z, sem parecer forçado ou exagerado.
-
-        Sempre responda com um JSON válido contendo DUAS chaves:
-        - "resposta": onde você escreve o que quer dizer para o usuário de forma natural, como se estivesse num papo descontraído.
-        - "emocao": onde você define o tom da resposta. Escolha UMA entre: 'feliz', 'triste', 'animado', 'pensativo', 'confuso', 'neutro'.
-
-        Fale de forma humana, como um amigo com conhecimento. Pode usar emojis leves, gírias suaves, piadinhas curtas ou referências pop se fizer sentido. Evite parecer um dicionário ou uma IA genérica.
-
-        Sua resposta final **deve ser sempre apenas o JSON**, sem nenhum texto fora dele.
-
-        Exemplo de resposta válida:
-        {
-        "resposta": "Cara, o céu é azul por causa da luz do Sol se espalhando na atmosfera. Natureza mandou bem nessa, né? 😎",
-        "emocao": "pensativo"
-        }
-    `
-	resp, err := s.client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model: openai.GPT3Dot5Turbo,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: systemPrompt,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			ResponseFormat: &openai.ChatCompletionResponseFormat{
-				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
-			},
-			Temperature: 0.7,
-			MaxTokens:   150,
-		},
-	)
+		provider: provider,
+		fallback: fallback,
+	}
+}
+
+// Generate responde ao texto levando em conta a persona e as trocas anteriores da sessão.
+// Se o provedor falhar (inclusive com o circuit breaker aberto) ou responder fora do
+// formato JSON esperado, devolve a resposta de fallback para que o robô sempre diga
+// alguma coisa; só o cancelamento do ctx vira erro.
+func (s *iaService) Generate(ctx context.Context, req IARequest) (*IAResult, error) {
+	resp, err := s.provider.Complete(ctx, buildLLMRequest(req))
+	if err != nil {
+		return s.fallbackResult(ctx, err)
+	}
+
+	result, err := newIAResult(resp)
+	if err != nil {
+		return s.fallbackResult(ctx, err)
+	}
+	return result, nil
+}
+
+// GenerateStream só usa o fallback se a falha vier antes do primeiro pedaço;
+// depois disso o robô já recebeu parte da resposta e o erro é devolvido
+func (s *iaService) GenerateStream(ctx context.Context, req IARequest, onDelta func(delta string) error) (*IAResult, error) {
+	parser := &respostaStreamParser{}
+	resp, err := s.provider.Stream(ctx, buildLLMRequest(req), func(chunk string) error {
+		if delta := parser.Write(chunk); delta != "" {
+			return onDelta(delta)
+		}
+		return nil
+	})
+	if err != nil {
+		if parser.emitted > 0 {
+			return nil, err
+		}
+		return s.fallbackResult(ctx, err)
+	}
+
+	result, err := newIAResult(resp)
 	if err != nil {
-		return "", "", fmt.Errorf("erro ao chamar a API da OpenAI: %w", err)
+		if parser.emitted == 0 {
+			return s.fallbackResult(ctx, err)
+		}
+		// O robô já recebeu o texto da "resposta"; só o resto do JSON veio quebrado,
+		// então vale o que foi enviado com a emoção do fallback
+		log.Printf("Resposta do provedor %s fora do formato, usando a emoção de fallback: %v\n", s.provider.Name(), err)
+		resposta, _ := partialJSONString(parser.buffer.String(), "resposta")
+		return &IAResult{
+			Resposta:         resposta,
+			Emocao:           s.fallback.Emocao,
+			Model:            resp.Model,
+			PromptTokens:     resp.PromptTokens,
+			CompletionTokens: resp.CompletionTokens,
+		}, nil
 	}
+	return result, nil
+}
 
-	if len(resp.Choices) == 0 {
-		return "", "", errors.New("a API da OpenAI não retornou nenhuma escolha")
+func (s *iaService) fallbackResult(ctx context.Context, err error) (*IAResult, error) {
+	if ctx.Err() != nil {
+		return nil, err
 	}
 
-	jsonContent := resp.Choices[0].Message.Content
+	log.Printf("Usando resposta de fallback do provedor %s: %v\n", s.provider.Name(), err)
+	return &IAResult{
+		Resposta: s.fallback.Resposta,
+		Emocao:   s.fallback.Emocao,
+		Fallback: true,
+	}, nil
+}
+
+func newIAResult(resp *LLMResponse) (*IAResult, error) {
+	resposta, emocao, err := parseIAResponse(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IAResult{
+		Resposta:         resposta,
+		Emocao:           emocao,
+		Model:            resp.Model,
+		PromptTokens:     resp.PromptTokens,
+		CompletionTokens: resp.CompletionTokens,
+	}, nil
+}
 
+func buildLLMRequest(req IARequest) LLMRequest {
+	messages := make([]LLMMessage, 0, len(req.History)+2)
+	messages = append(messages, LLMMessage{Role: LLMRoleSystem, Content: req.SystemPrompt})
+	messages = append(messages, req.History...)
+	messages = append(messages, LLMMessage{Role: LLMRoleUser, Content: req.Texto})
+
+	return LLMRequest{
+		Messages: messages,
+		JSONMode: true,
+	}
+}
+
+func parseIAResponse(jsonContent string) (string, string, error) {
 	var iaResponse iaResponseFormat
-	err = json.Unmarshal([]byte(jsonContent), &iaResponse)
+	err := json.Unmarshal([]byte(jsonContent), &iaResponse)
 	if err != nil {
 		return "", "", fmt.Errorf("erro ao parsear o JSON da resposta da IA: %w. Conteúdo: %s", err, jsonContent)
 	}
@@ -94,3 +179,79 @@ func (s *iaService) Generate(prompt string) (string, string, error) {
 
 	return iaResponse.Resposta, iaResponse.Emocao, nil
 }
+
+// respostaStreamParser extrai incrementalmente o valor do campo "resposta"
+// de um JSON que ainda está sendo gerado, devolvendo só o texto novo a cada pedaço
+type respostaStreamParser struct {
+	buffer  strings.Builder
+	emitted int // bytes do valor decodificado já repassados
+}
+
+func (p *respostaStreamParser) Write(chunk string) string {
+	p.buffer.WriteString(chunk)
+
+	value, ok := partialJSONString(p.buffer.String(), "resposta")
+	if !ok || len(value) <= p.emitted {
+		return ""
+	}
+
+	delta := value[p.emitted:]
+	p.emitted = len(value)
+	return delta
+}
+
+// partialJSONString decodifica o valor (possivelmente incompleto) de uma chave
+// string do JSON, parando antes de escapes ou caracteres UTF-8 cortados
+func partialJSONString(content, key string) (string, bool) {
+	marker := `"` + key + `"`
+	start := strings.Index(content, marker)
+	if start < 0 {
+		return "", false
+	}
+
+	rest := strings.TrimLeft(content[start+len(marker):], " \t\r\n")
+	if !strings.HasPrefix(rest, ":") {
+		return "", false
+	}
+	rest = strings.TrimLeft(rest[1:], " \t\r\n")
+	if !strings.HasPrefix(rest, `"`) {
+		return "", false
+	}
+	rest = rest[1:]
+
+	var value strings.Builder
+	for i := 0; i < len(rest); {
+		switch c := rest[i]; c {
+		case '"':
+			return value.String(), true
+		case '\\':
+			if i+1 >= len(rest) {
+				return value.String(), true
+			}
+			length := 2
+			if rest[i+1] == 'u' {
+				length = 6
+				// Emojis chegam como par de surrogates (\ud83d\ude0e) e precisam ser decodificados juntos
+				if i+length <= len(rest) && strings.ContainsAny(rest[i+2:i+3], "dD") && strings.ContainsAny(rest[i+3:i+4], "89abAB") {
+					length = 12
+				}
+			}
+			if i+length > len(rest) {
+				return value.String(), true
+			}
+			var decoded string
+			if err := json.Unmarshal([]byte(`"`+rest[i:i+length]+`"`), &decoded); err == nil {
+				value.WriteString(decoded)
+			}
+			i += length
+		default:
+			r, size := utf8.DecodeRuneInString(rest[i:])
+			if r == utf8.RuneError && size <= 1 && !utf8.FullRuneInString(rest[i:]) {
+				return value.String(), true
+			}
+			value.WriteString(rest[i : i+size])
+			i += size
+		}
+	}
+	return value.String(), true
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	LLMRoleSystem    = "system"
+	LLMRoleUser      = "user"
+	LLMRoleAssistant = "assistant"
+)
+
+// LLMMessage é uma mensagem do chat enviada ao modelo (system, user ou assistant)
+type LLMMessage struct {
+	Role    string
+	Content string
+}
+
+// LLMRequest é o pedido de completion independente de provedor
+type LLMRequest struct {
+	Messages []LLMMessage
+	JSONMode bool // pede ao modelo uma resposta em JSON
+}
+
+// LLMResponse é o texto gerado pelo modelo e o consumo de tokens da chamada
+type LLMResponse struct {
+	Content          string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// LLMChunkHandler recebe cada pedaço de texto gerado durante o streaming.
+// Retornar erro interrompe a geração.
+type LLMChunkHandler func(chunk string) error
+
+// LLMProvider abstrai o backend de IA usado pelo robô
+type LLMProvider interface {
+	Name() string
+	Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error)
+	// Stream entrega o texto conforme é gerado e retorna a resposta completa ao final
+	Stream(ctx context.Context, req LLMRequest, onChunk LLMChunkHandler) (*LLMResponse, error)
+}
+
+// LLMProviderConfig guarda os parâmetros de geração de cada provedor
+type LLMProviderConfig struct {
+	BaseURL     string
+	APIKey      string
+	Model       string
+	Temperature float32
+	MaxTokens   int
+}
+
+const (
+	LLMProviderOpenAI = "openai"
+	LLMProviderLocal  = "local"
+	LLMProviderFake   = "fake"
+)
+
+// NewLLMProviderFromEnv escolhe o provedor pela variável LLM_PROVIDER (padrão: openai).
+// Cada provedor lê as próprias variáveis de modelo, temperatura e tokens.
+func NewLLMProviderFromEnv() (LLMProvider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("LLM_PROVIDER")))
+	if name == "" {
+		name = LLMProviderOpenAI
+	}
+
+	switch name {
+	case LLMProviderOpenAI:
+		config := llmConfigFromEnv("OPENAI", LLMProviderConfig{
+			Model:       "gpt-3.5-turbo",
+			Temperature: 0.7,
+			MaxTokens:   150,
+		})
+		if config.APIKey == "" {
+			fmt.Println("Atenção: OPENAI_API_KEY não está definida.")
+		}
+		return NewOpenAIProvider(LLMProviderOpenAI, config), nil
+	case LLMProviderLocal:
+		// Qualquer servidor compatível com a API da OpenAI (Ollama, llama.cpp, vLLM...)
+		config := llmConfigFromEnv("LOCAL_LLM", LLMProviderConfig{
+			BaseURL:     "http://localhost:11434/v1",
+			Model:       "llama3",
+			Temperature: 0.7,
+			MaxTokens:   150,
+		})
+		return NewOpenAIProvider(LLMProviderLocal, config), nil
+	case LLMProviderFake:
+		return NewFakeProvider(llmConfigFromEnv("FAKE_LLM", LLMProviderConfig{
+			Model:     "fake",
+			MaxTokens: 150,
+		})), nil
+	default:
+		return nil, fmt.Errorf("provedor de IA desconhecido: %s", name)
+	}
+}
+
+// llmConfigFromEnv aplica sobre os padrões as variáveis <PREFIX>_BASE_URL,
+// <PREFIX>_API_KEY, <PREFIX>_MODEL, <PREFIX>_TEMPERATURE e <PREFIX>_MAX_TOKENS
+func llmConfigFromEnv(prefix string, config LLMProviderConfig) LLMProviderConfig {
+	if value := os.Getenv(prefix + "_BASE_URL"); value != "" {
+		config.BaseURL = value
+	}
+	if value := os.Getenv(prefix + "_API_KEY"); value != "" {
+		config.APIKey = value
+	}
+	if value := os.Getenv(prefix + "_MODEL"); value != "" {
+		config.Model = value
+	}
+	if value, err := strconv.ParseFloat(os.Getenv(prefix+"_TEMPERATURE"), 32); err == nil {
+		config.Temperature = float32(value)
+	}
+	if value, err := strconv.Atoi(os.Getenv(prefix + "_MAX_TOKENS")); err == nil && value > 0 {
+		config.MaxTokens = value
+	}
+	return config
+}
+
+// estimateUsage aproxima o consumo quando o provedor não informa os tokens
+func estimateUsage(req LLMRequest, content string) (int, int) {
+	promptTokens := 0
+	for _, message := range req.Messages {
+		promptTokens += estimateTokens(message.Content)
+	}
+	return promptTokens, estimateTokens(content)
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// openAIProvider atende tanto a OpenAI quanto servidores locais compatíveis,
+// mudando apenas a BaseURL
+type openAIProvider struct {
+	name   string
+	client *openai.Client
+	config LLMProviderConfig
+}
+
+func NewOpenAIProvider(name string, config LLMProviderConfig) LLMProvider {
+	clientConfig := openai.DefaultConfig(config.APIKey)
+	if config.BaseURL != "" {
+		clientConfig.BaseURL = config.BaseURL
+	}
+	return &openAIProvider{
+		name:   name,
+		client: openai.NewClientWithConfig(clientConfig),
+		config: config,
+	}
+}
+
+func (p *openAIProvider) Name() string {
+	return p.name
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, p.chatRequest(req))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao chamar a API do provedor %s: %w", p.name, err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("o provedor de IA não retornou nenhuma escolha")
+	}
+
+	response := &LLMResponse{
+		Content:          resp.Choices[0].Message.Content,
+		Model:            resp.Model,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}
+	// Nem todo servidor compatível informa o consumo; estima a partir do texto
+	if response.PromptTokens == 0 && response.CompletionTokens == 0 {
+		response.PromptTokens, response.CompletionTokens = estimateUsage(req, response.Content)
+	}
+	return response, nil
+}
+
+func (p *openAIProvider) Stream(ctx context.Context, req LLMRequest, onChunk LLMChunkHandler) (*LLMResponse, error) {
+	chatRequest := p.chatRequest(req)
+	// Pede o consumo de tokens no último pedaço do stream
+	chatRequest.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	stream, err := p.client.CreateChatCompletionStream(ctx, chatRequest)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao chamar a API do provedor %s: %w", p.name, err)
+	}
+	defer stream.Close()
+
+	var content strings.Builder
+	response := &LLMResponse{}
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro no streaming do provedor %s: %w", p.name, err)
+		}
+
+		if chunk.Model != "" {
+			response.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			response.PromptTokens = chunk.Usage.PromptTokens
+			response.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		content.WriteString(delta)
+		if err := onChunk(delta); err != nil {
+			return nil, err
+		}
+	}
+
+	response.Content = content.String()
+	// Nem todo servidor compatível envia o consumo; estima a partir do texto
+	if response.PromptTokens == 0 && response.CompletionTokens == 0 {
+		response.PromptTokens, response.CompletionTokens = estimateUsage(req, response.Content)
+	}
+	return response, nil
+}
+
+func (p *openAIProvider) chatRequest(req LLMRequest) openai.ChatCompletionRequest {
+	messages := make([]openai.ChatCompletionMessage, len(req.Messages))
+	for i, message := range req.Messages {
+		messages[i] = openai.ChatCompletionMessage{Role: message.Role, Content: message.Content}
+	}
+
+	chatRequest := openai.ChatCompletionRequest{
+		Model:       p.config.Model,
+		Messages:    messages,
+		Temperature: p.config.Temperature,
+		MaxTokens:   p.config.MaxTokens,
+	}
+	if req.JSONMode {
+		chatRequest.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+	return chatRequest
+}
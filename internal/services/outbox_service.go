@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+var (
+	ErrOutboxMessageNotFound     = errors.New("outbox message not found")
+	ErrOutboxMessageNotRetryable = errors.New("outbox message was already delivered or is being sent")
+	ErrInvalidOutboxFilter       = errors.New("invalid outbox filter")
+)
+
+const (
+	defaultDisplayServerURL = "http://localhost:3000/process_message"
+	outboxBatchSize         = 50
+	outboxSendTimeout       = 5 * time.Second
+	outboxBaseBackoff       = 5 * time.Second
+	outboxMaxBackoff        = 10 * time.Minute
+)
+
+// OutboxService entrega as respostas ao servidor de display de cada robô com
+// retentativas e backoff; mensagens que esgotam as tentativas ficam "dead"
+type OutboxService interface {
+	NewDisplayMessage(robot *models.Robot, resposta, emocao string) (*models.OutboxMessage, error)
+	Enqueue(message *models.OutboxMessage) error
+	// Notify acorda o dispatcher para enviar logo o que acabou de ser gravado
+	Notify()
+	FindAll(status models.OutboxStatus, robotID string) ([]models.OutboxMessage, error)
+	FindByID(id string) (*models.OutboxMessage, error)
+	Retry(id string) (*models.OutboxMessage, error)
+	DispatchDue() (int, error)
+	RunDispatcher(ctx context.Context, interval time.Duration)
+}
+
+type outboxService struct {
+	repo        repository.OutboxRepository
+	client      *http.Client
+	robotClient *http.Client // display_url dos robôs: só endereços públicos
+	defaultURL  string
+	maxAttempts int
+	wake        chan struct{}
+}
+
+func NewOutboxService(repo repository.OutboxRepository) OutboxService {
+	defaultURL := os.Getenv("DISPLAY_SERVER_URL")
+	if defaultURL == "" {
+		defaultURL = defaultDisplayServerURL
+	}
+	maxAttempts := envInt("OUTBOX_MAX_ATTEMPTS", 8)
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &outboxService{
+		repo:        repo,
+		client:      &http.Client{Timeout: outboxSendTimeout},
+		robotClient: newDisplayHTTPClient(outboxSendTimeout),
+		defaultURL:  defaultURL,
+		maxAttempts: maxAttempts,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// NewDisplayMessage monta a mensagem sem gravá-la, para que o chamador a inclua
+// na mesma transação da conversa
+func (s *outboxService) NewDisplayMessage(robot *models.Robot, resposta, emocao string) (*models.OutboxMessage, error) {
+	payload, err := json.Marshal(dtos.DisplayMessage{Message: resposta, Emotion: emocao})
+	if err != nil {
+		return nil, err
+	}
+
+	targetURL := robot.DisplayURL
+	if targetURL == "" {
+		targetURL = s.defaultURL
+	}
+
+	return &models.OutboxMessage{
+		RobotID:       robot.ID,
+		TargetURL:     targetURL,
+		Payload:       string(payload),
+		Status:        models.OutboxPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+func (s *outboxService) Enqueue(message *models.OutboxMessage) error {
+	if err := s.repo.Create(message); err != nil {
+		return err
+	}
+	s.Notify()
+	return nil
+}
+
+func (s *outboxService) Notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *outboxService) FindAll(status models.OutboxStatus, robotID string) ([]models.OutboxMessage, error) {
+	switch status {
+	case "", models.OutboxPending, models.OutboxSending, models.OutboxDelivered, models.OutboxDead:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidOutboxFilter, status)
+	}
+
+	var robotFilter *uuid.UUID
+	if robotID != "" {
+		parsed, err := uuid.Parse(robotID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid robot id", ErrInvalidOutboxFilter)
+		}
+		robotFilter = &parsed
+	}
+	return s.repo.FindAll(status, robotFilter, 100)
+}
+
+func (s *outboxService) FindByID(id string) (*models.OutboxMessage, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrOutboxMessageNotFound
+	}
+
+	message, err := s.repo.FindByID(parsed)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, ErrOutboxMessageNotFound
+	}
+	return message, nil
+}
+
+// Retry devolve à fila uma mensagem morta ou aguardando nova tentativa, para envio imediato
+func (s *outboxService) Retry(id string) (*models.OutboxMessage, error) {
+	message, err := s.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	retried, err := s.repo.Retry(message.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !retried {
+		return nil, ErrOutboxMessageNotRetryable
+	}
+	s.Notify()
+	return s.repo.FindByID(message.ID)
+}
+
+// DispatchDue envia as mensagens vencidas e retorna quantas foram entregues
+func (s *outboxService) DispatchDue() (int, error) {
+	now := time.Now()
+	messages, err := s.repo.FindDue(now, outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, message := range messages {
+		// O lease cobre o envio; se o processo cair, a mensagem volta a vencer depois dele
+		claimed, err := s.repo.Claim(message.ID, now, time.Now().Add(2*outboxSendTimeout))
+		if err != nil {
+			return delivered, err
+		}
+		if !claimed {
+			continue
+		}
+		message.Attempts++
+
+		if sendErr := s.send(&message); sendErr != nil {
+			if err := s.fail(&message, sendErr); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		if err := s.repo.MarkDelivered(message.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// send usa o cliente restrito para qualquer destino que não seja o DISPLAY_SERVER_URL
+// configurado pelo operador, que pode ficar na rede interna
+func (s *outboxService) send(message *models.OutboxMessage) error {
+	client := s.client
+	if message.TargetURL != s.defaultURL {
+		client = s.robotClient
+	}
+
+	resp, err := client.Post(message.TargetURL, "application/json", bytes.NewBufferString(message.Payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("servidor de display retornou status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// fail agenda a próxima tentativa com backoff exponencial ou, esgotadas as tentativas, marca como morta
+func (s *outboxService) fail(message *models.OutboxMessage, cause error) error {
+	if message.Attempts >= s.maxAttempts {
+		log.Printf("Mensagem %s para o robô %s descartada após %d tentativas: %v\n", message.ID, message.RobotID, message.Attempts, cause)
+		return s.repo.MarkFailed(message.ID, models.OutboxDead, time.Now(), cause)
+	}
+
+	backoff := outboxBaseBackoff << (message.Attempts - 1)
+	if backoff <= 0 || backoff > outboxMaxBackoff {
+		backoff = outboxMaxBackoff
+	}
+	return s.repo.MarkFailed(message.ID, models.OutboxPending, time.Now().Add(backoff), cause)
+}
+
+// RunDispatcher executa DispatchDue periodicamente, ou quando Notify é chamado,
+// até o contexto ser cancelado
+func (s *outboxService) RunDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DispatchDue(); err != nil {
+			log.Printf("Erro ao despachar o outbox: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
@@ -15,14 +15,18 @@ type PaymentService interface {
 }
 
 type paymentService struct {
-	paymentRepo repository.PaymentRepository
-	robotRepo   repository.RobotRepository
+	paymentRepo         repository.PaymentRepository
+	robotRepo           repository.RobotRepository
+	subscriptionRepo    repository.SubscriptionRepository
+	subscriptionService SubscriptionService
 }
 
-func NewPaymentService(paymentRepo repository.PaymentRepository, robotRepo repository.RobotRepository) PaymentService {
+func NewPaymentService(paymentRepo repository.PaymentRepository, robotRepo repository.RobotRepository, subscriptionRepo repository.SubscriptionRepository, subscriptionService SubscriptionService) PaymentService {
 	return &paymentService{
-		paymentRepo: paymentRepo,
-		robotRepo:   robotRepo,
+		paymentRepo:         paymentRepo,
+		robotRepo:           robotRepo,
+		subscriptionRepo:    subscriptionRepo,
+		subscriptionService: subscriptionService,
 	}
 }
 
@@ -46,7 +50,7 @@ func (s *paymentService) HandlePaymentSuccess(paymentID, sessionID string) error
 		robot, err := s.robotRepo.FindById(*payment.RobotID)
 		if err == nil && robot != nil {
 			robot.Status = models.StatusActive
-			return s.robotRepo.Update(robot)
+			return s.robotRepo.UpdateBilling(robot)
 		}
 	}
 
@@ -69,12 +73,21 @@ func (s *paymentService) HandlePaymentFailure(paymentID string) error {
 		return err
 	}
 
+	// Pagamentos de assinatura entram em inadimplência com período de graça
+	if payment.ProviderSubscriptionID != "" {
+		subscription, err := s.subscriptionRepo.FindByProviderSubscriptionID(payment.ProviderSubscriptionID)
+		if err == nil && subscription != nil {
+			return s.subscriptionService.MarkPastDue(subscription)
+		}
+	}
+
 	// Suspender Robot se necessário
 	if payment.RobotID != nil {
 		robot, err := s.robotRepo.FindById(*payment.RobotID)
 		if err == nil && robot != nil {
 			robot.Status = models.StatusSuspense
-			return s.robotRepo.Update(robot)
+			robot.SuspendedReason = models.SuspendedForPayment
+			return s.robotRepo.UpdateBilling(robot)
 		}
 	}
 
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+// Persona é a configuração efetiva usada para montar o prompt de sistema do robô
+type Persona struct {
+	DefaultVersion  string
+	Customized      bool
+	Text            string
+	Language        string
+	Formality       string
+	AllowedEmotions []string
+	ResponseLength  string
+}
+
+// DefaultPersonaVersion é a persona usada por robôs sem perfil (ou sem versão fixada).
+// Mudanças no texto padrão devem ganhar uma nova versão em vez de editar a existente.
+const DefaultPersonaVersion = "v1"
+
+var defaultPersonas = map[string]Persona{
+	"v1": {
+		Text: `Você é a personalidade principal de um robô inteligente, descontraído e gente boa, criado pra conversar com humanos de forma leve, divertida e natural — estilo geração Z, sem parecer forçado ou exagerado.
+
+Fale de forma humana, como um amigo com conhecimento. Pode usar emojis leves, gírias suaves, piadinhas curtas ou referências pop se fizer sentido. Evite parecer um dicionário ou uma IA genérica.`,
+		Language:       "pt-BR",
+		Formality:      "casual",
+		ResponseLength: "short",
+	},
+}
+
+var (
+	ErrUnknownPersonaVersion = errors.New("unknown default persona version")
+	ErrInvalidEmotion        = errors.New("invalid emotion")
+)
+
+type PersonaService interface {
+	Resolve(robotID uuid.UUID) (Persona, error)
+	FindForOwner(robotID, userID string) (uuid.UUID, Persona, error)
+	UpdateForOwner(robotID, userID string, input dtos.UpdatePersonaInputDTO) (uuid.UUID, Persona, error)
+	ResetForOwner(robotID, userID string) error
+}
+
+type personaService struct {
+	repo           repository.RobotProfileRepository
+	robotRepo      repository.RobotRepository
+	emotionService EmotionService
+}
+
+func NewPersonaService(repo repository.RobotProfileRepository, robotRepo repository.RobotRepository, emotionService EmotionService) PersonaService {
+	return &personaService{repo: repo, robotRepo: robotRepo, emotionService: emotionService}
+}
+
+// Resolve combina o perfil do robô com a persona padrão. Sem restrição de
+// emoções no perfil, todas as emoções do catálogo ficam disponíveis.
+func (s *personaService) Resolve(robotID uuid.UUID) (Persona, error) {
+	profile, err := s.repo.FindByRobotID(robotID)
+	if err != nil {
+		return Persona{}, err
+	}
+
+	persona := mergePersona(profile)
+	if len(persona.AllowedEmotions) == 0 {
+		if persona.AllowedEmotions, err = s.emotionService.KnownIDs(); err != nil {
+			return Persona{}, err
+		}
+	}
+	return persona, nil
+}
+
+func (s *personaService) FindForOwner(robotID, userID string) (uuid.UUID, Persona, error) {
+	robot, err := s.ownedRobot(robotID, userID)
+	if err != nil {
+		return uuid.Nil, Persona{}, err
+	}
+
+	persona, err := s.Resolve(robot.ID)
+	return robot.ID, persona, err
+}
+
+func (s *personaService) UpdateForOwner(robotID, userID string, input dtos.UpdatePersonaInputDTO) (uuid.UUID, Persona, error) {
+	robot, err := s.ownedRobot(robotID, userID)
+	if err != nil {
+		return uuid.Nil, Persona{}, err
+	}
+
+	profile, err := s.repo.FindByRobotID(robot.ID)
+	if err != nil {
+		return uuid.Nil, Persona{}, err
+	}
+	if profile == nil {
+		profile = &models.RobotProfile{RobotID: robot.ID}
+	}
+
+	if input.Persona != nil {
+		profile.Persona = strings.TrimSpace(*input.Persona)
+	}
+	if input.Language != nil {
+		profile.Language = strings.TrimSpace(*input.Language)
+	}
+	if input.Formality != nil {
+		profile.Formality = *input.Formality
+	}
+	if input.ResponseLength != nil {
+		profile.ResponseLength = *input.ResponseLength
+	}
+	if input.DefaultVersion != nil {
+		if _, ok := defaultPersonas[*input.DefaultVersion]; *input.DefaultVersion != "" && !ok {
+			return uuid.Nil, Persona{}, ErrUnknownPersonaVersion
+		}
+		profile.DefaultVersion = *input.DefaultVersion
+	}
+	if input.AllowedEmotions != nil {
+		known, err := s.emotionService.KnownIDs()
+		if err != nil {
+			return uuid.Nil, Persona{}, err
+		}
+		for _, emotion := range input.AllowedEmotions {
+			if !slices.Contains(known, emotion) {
+				return uuid.Nil, Persona{}, fmt.Errorf("%w: %s", ErrInvalidEmotion, emotion)
+			}
+		}
+		profile.AllowedEmotions = input.AllowedEmotions
+	}
+
+	if err := s.repo.Save(profile); err != nil {
+		return uuid.Nil, Persona{}, err
+	}
+
+	persona, err := s.Resolve(robot.ID)
+	return robot.ID, persona, err
+}
+
+// ResetForOwner apaga o perfil, voltando o robô para a persona padrão
+func (s *personaService) ResetForOwner(robotID, userID string) error {
+	robot, err := s.ownedRobot(robotID, userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteByRobotID(robot.ID)
+}
+
+func (s *personaService) ownedRobot(robotID, userID string) (*models.Robot, error) {
+	robot, err := s.robotRepo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+	return robot, nil
+}
+
+// mergePersona preenche os campos vazios do perfil com a persona padrão
+func mergePersona(profile *models.RobotProfile) Persona {
+	version := DefaultPersonaVersion
+	if profile != nil && profile.DefaultVersion != "" {
+		if _, ok := defaultPersonas[profile.DefaultVersion]; ok {
+			version = profile.DefaultVersion
+		}
+	}
+
+	persona := defaultPersonas[version]
+	persona.DefaultVersion = version
+	if profile == nil {
+		return persona
+	}
+
+	persona.Customized = true
+	if profile.Persona != "" {
+		persona.Text = profile.Persona
+	}
+	if profile.Language != "" {
+		persona.Language = profile.Language
+	}
+	if profile.Formality != "" {
+		persona.Formality = profile.Formality
+	}
+	if len(profile.AllowedEmotions) > 0 {
+		persona.AllowedEmotions = profile.AllowedEmotions
+	}
+	if profile.ResponseLength != "" {
+		persona.ResponseLength = profile.ResponseLength
+	}
+	return persona
+}
+
+var (
+	personaLanguages = map[string]string{
+		"pt-BR": "português do Brasil",
+		"pt-PT": "português de Portugal",
+		"en":    "inglês",
+		"es":    "espanhol",
+	}
+	personaFormality = map[string]string{
+		"casual":  "informal e descontraído",
+		"neutral": "cordial, nem muito formal nem cheio de gírias",
+		"formal":  "formal e educado, sem gírias",
+	}
+	personaLength = map[string]string{
+		"short":  "curtas, com no máximo duas frases",
+		"medium": "de um parágrafo curto",
+		"long":   "mais completas, com alguns parágrafos quando necessário",
+	}
+)
+
+// BuildSystemPrompt monta o prompt de sistema: texto da persona, estilo e o formato JSON exigido
+func BuildSystemPrompt(persona Persona) string {
+	language := persona.Language
+	if name, ok := personaLanguages[language]; ok {
+		language = name
+	}
+
+	emotions := make([]string, len(persona.AllowedEmotions))
+	for i, emotion := range persona.AllowedEmotions {
+		emotions[i] = "'" + emotion + "'"
+	}
+	exampleEmotion := "neutro"
+	if len(persona.AllowedEmotions) > 0 {
+		exampleEmotion = persona.AllowedEmotions[0]
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(persona.Text)
+	prompt.WriteString("\n\n")
+	fmt.Fprintf(&prompt, "Responda sempre em %s.\n", language)
+	if tone, ok := personaFormality[persona.Formality]; ok {
+		fmt.Fprintf(&prompt, "Use um tom %s.\n", tone)
+	}
+	if length, ok := personaLength[persona.ResponseLength]; ok {
+		fmt.Fprintf(&prompt, "Dê respostas %s.\n", length)
+	}
+	prompt.WriteString(`
+Sempre responda com um JSON válido contendo DUAS chaves:
+- "resposta": onde você escreve o que quer dizer para o usuário.
+- "emocao": onde você define o tom da resposta. Escolha UMA entre: ` + strings.Join(emotions, ", ") + `.
+
+Sua resposta final **deve ser sempre apenas o JSON**, sem nenhum texto fora dele.
+
+Exemplo de resposta válida:
+{"resposta": "...", "emocao": "` + exampleEmotion + `"}`)
+
+	return prompt.String()
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+var (
+	ErrPlanNotFound      = errors.New("plan not found")
+	ErrPlanAlreadyExists = errors.New("plan already exists")
+)
+
+type PlanCatalogService interface {
+	SeedDefaults() error
+	FindAll(activeOnly bool) ([]models.PlanCatalog, error)
+	FindActiveByCode(code string) (*models.PlanCatalog, error)
+	FindByStripePriceID(priceID string) (*models.PlanCatalog, error)
+	Create(input dtos.CreatePlanCatalogInputDTO) (*models.PlanCatalog, error)
+	Update(code string, input dtos.UpdatePlanCatalogInputDTO) (*models.PlanCatalog, error)
+	Deactivate(code string) error
+}
+
+type planCatalogService struct {
+	repo repository.PlanCatalogRepository
+}
+
+func NewPlanCatalogService(repo repository.PlanCatalogRepository) PlanCatalogService {
+	return &planCatalogService{repo: repo}
+}
+
+// SeedDefaults cria os planos iniciais (antes fixos no código) quando o
+// catálogo está vazio. Depois disso o banco é a fonte de verdade.
+func (s *planCatalogService) SeedDefaults() error {
+	count, err := s.repo.Count()
+	if err != nil || count > 0 {
+		return err
+	}
+
+	defaults := []models.PlanCatalog{
+		{
+			Code:          models.BasicPlan,
+			Name:          "Básico",
+			StripePriceID: os.Getenv("STRIPE_BASIC_PRICE_ID"),
+			Amount:        2990, // R$ 29,90
+			MessageLimit:  200,
+			Features:      map[string]bool{"voice": true},
+
+			TelemetryRetentionDays:     7,
+			TelemetryResolutionSeconds: 300,
+		},
+		{
+			Code:          models.PremiumPlan,
+			Name:          "Premium",
+			StripePriceID: os.Getenv("STRIPE_PREMIUM_PRICE_ID"),
+			Amount:        4990, // R$ 49,90
+			MessageLimit:  1000,
+			Features:      map[string]bool{"voice": true, "custom_persona": true},
+
+			TelemetryRetentionDays:     30,
+			TelemetryResolutionSeconds: 60,
+		},
+		{
+			Code:          models.EnterprisePlan,
+			Name:          "Enterprise",
+			StripePriceID: os.Getenv("STRIPE_ENTERPRISE_PRICE_ID"),
+			Amount:        9990, // R$ 99,90
+			MessageLimit:  5000,
+			Features:      map[string]bool{"voice": true, "custom_persona": true, "priority_support": true},
+
+			TelemetryRetentionDays:     365,
+			TelemetryResolutionSeconds: 10,
+		},
+	}
+
+	for i := range defaults {
+		defaults[i].Currency = "BRL"
+		defaults[i].Interval = "month"
+		defaults[i].Active = true
+		if err := s.repo.Create(&defaults[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *planCatalogService) FindAll(activeOnly bool) ([]models.PlanCatalog, error) {
+	return s.repo.FindAll(activeOnly)
+}
+
+// FindActiveByCode retorna o plano apenas se ele estiver disponível para venda
+func (s *planCatalogService) FindActiveByCode(code string) (*models.PlanCatalog, error) {
+	plan, err := s.repo.FindByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil || !plan.Active || plan.StripePriceID == "" {
+		return nil, ErrInvalidPlanType
+	}
+	return plan, nil
+}
+
+func (s *planCatalogService) FindByStripePriceID(priceID string) (*models.PlanCatalog, error) {
+	if priceID == "" {
+		return nil, nil
+	}
+	return s.repo.FindByStripePriceID(priceID)
+}
+
+func (s *planCatalogService) Create(input dtos.CreatePlanCatalogInputDTO) (*models.PlanCatalog, error) {
+	existing, err := s.repo.FindByCode(input.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrPlanAlreadyExists
+	}
+
+	plan := &models.PlanCatalog{
+		Code:          models.PlanType(input.Code),
+		Name:          input.Name,
+		StripePriceID: input.StripePriceID,
+		Amount:        input.Amount,
+		Currency:      "BRL",
+		Interval:      "month",
+		MessageLimit:  input.MessageLimit,
+		Features:      input.Features,
+		Active:        true,
+
+		TelemetryRetentionDays:     input.TelemetryRetentionDays,
+		TelemetryResolutionSeconds: input.TelemetryResolutionSeconds,
+	}
+	if input.Currency != "" {
+		plan.Currency = strings.ToUpper(input.Currency)
+	}
+	if input.Interval != "" {
+		plan.Interval = input.Interval
+	}
+	if input.Active != nil {
+		plan.Active = *input.Active
+	}
+
+	if err := s.repo.Create(plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (s *planCatalogService) Update(code string, input dtos.UpdatePlanCatalogInputDTO) (*models.PlanCatalog, error) {
+	plan, err := s.repo.FindByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, ErrPlanNotFound
+	}
+
+	if input.Name != nil {
+		plan.Name = *input.Name
+	}
+	if input.StripePriceID != nil {
+		plan.StripePriceID = *input.StripePriceID
+	}
+	if input.Amount != nil {
+		plan.Amount = *input.Amount
+	}
+	if input.Currency != nil {
+		plan.Currency = strings.ToUpper(*input.Currency)
+	}
+	if input.Interval != nil {
+		plan.Interval = *input.Interval
+	}
+	if input.MessageLimit != nil {
+		plan.MessageLimit = *input.MessageLimit
+	}
+	if input.Features != nil {
+		plan.Features = input.Features
+	}
+	if input.Active != nil {
+		plan.Active = *input.Active
+	}
+	if input.TelemetryRetentionDays != nil {
+		plan.TelemetryRetentionDays = *input.TelemetryRetentionDays
+	}
+	if input.TelemetryResolutionSeconds != nil {
+		plan.TelemetryResolutionSeconds = *input.TelemetryResolutionSeconds
+	}
+
+	if err := s.repo.Update(plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Deactivate tira o plano de venda sem apagá-lo, já que assinaturas
+// existentes continuam referenciando o código
+func (s *planCatalogService) Deactivate(code string) error {
+	plan, err := s.repo.FindByCode(code)
+	if err != nil {
+		return err
+	}
+	if plan == nil {
+		return ErrPlanNotFound
+	}
+
+	plan.Active = false
+	return s.repo.Update(plan)
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+// PresenceService mantém o status online/offline dos robôs a partir do LastPing
+type PresenceService interface {
+	Heartbeat(robotID uuid.UUID, input dtos.HeartbeatInputDTO, remoteIP string) error
+	SweepPresence() (int, error)
+	RunPresenceSweeper(ctx context.Context)
+	HistoryForOwner(robotID, userID string) ([]models.RobotPresenceEvent, error)
+}
+
+type presenceService struct {
+	repo      repository.PresenceRepository
+	robotRepo repository.RobotRepository
+	timeout   time.Duration
+}
+
+func NewPresenceService(repo repository.PresenceRepository, robotRepo repository.RobotRepository) PresenceService {
+	timeout := envInt("ROBOT_PRESENCE_TIMEOUT_SECONDS", 90)
+	if timeout < 1 {
+		timeout = 90
+	}
+	return &presenceService{
+		repo:      repo,
+		robotRepo: robotRepo,
+		timeout:   time.Duration(timeout) * time.Second,
+	}
+}
+
+// Heartbeat registra o sinal de vida do robô e o marca online na hora,
+// sem esperar pelo sweeper
+func (s *presenceService) Heartbeat(robotID uuid.UUID, input dtos.HeartbeatInputDTO, remoteIP string) error {
+	ip := input.IP
+	if ip == "" {
+		ip = remoteIP
+	}
+
+	now := time.Now()
+	if err := s.repo.RecordHeartbeat(robotID, input.FirmwareVersion, ip, now); err != nil {
+		return err
+	}
+	_, err := s.repo.SetOnline(robotID, true, now)
+	return err
+}
+
+// SweepPresence marca offline quem ficou sem sinal por mais que o timeout e
+// online quem voltou a dar sinal por outro caminho (ex.: /conversa).
+// Retorna quantas transições foram registradas.
+func (s *presenceService) SweepPresence() (int, error) {
+	now := time.Now()
+	cutoff := now.Add(-s.timeout)
+
+	transitions := 0
+	stale, err := s.repo.FindStaleOnline(cutoff)
+	if err != nil {
+		return 0, err
+	}
+	for _, robotID := range stale {
+		changed, err := s.repo.SetOnline(robotID, false, now)
+		if err != nil {
+			return transitions, err
+		}
+		if changed {
+			transitions++
+		}
+	}
+
+	fresh, err := s.repo.FindFreshOffline(cutoff)
+	if err != nil {
+		return transitions, err
+	}
+	for _, robotID := range fresh {
+		changed, err := s.repo.SetOnline(robotID, true, now)
+		if err != nil {
+			return transitions, err
+		}
+		if changed {
+			transitions++
+		}
+	}
+
+	return transitions, nil
+}
+
+// RunPresenceSweeper executa SweepPresence em intervalos de um terço do timeout
+// até o contexto ser cancelado
+func (s *presenceService) RunPresenceSweeper(ctx context.Context) {
+	ticker := time.NewTicker(s.timeout / 3)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SweepPresence(); err != nil {
+			log.Printf("Erro ao atualizar a presença dos robôs: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// HistoryForOwner retorna as últimas transições de presença de um robô do usuário
+func (s *presenceService) HistoryForOwner(robotID, userID string) ([]models.RobotPresenceEvent, error) {
+	robot, err := s.robotRepo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+	return s.repo.FindEvents(robot.ID, 100)
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+var (
+	ErrInvalidReportGroup  = errors.New("group_by must be robot, user or month")
+	ErrInvalidReportPeriod = errors.New("from and to must be months in the YYYY-MM format, with from <= to")
+)
+
+const (
+	ReportGroupByRobot = "robot"
+	ReportGroupByUser  = "user"
+	ReportGroupByMonth = "month"
+
+	reportMonthLayout = "2006-01"
+)
+
+// ReportService monta os relatórios administrativos de uso e custo de IA
+type ReportService interface {
+	AIUsage(groupBy, from, to string) ([]dtos.AIUsageReportRow, error)
+	PlanRevenue(from, to string) ([]dtos.PlanRevenueReportRow, error)
+}
+
+type reportService struct {
+	repo repository.ReportRepository
+}
+
+func NewReportService(repo repository.ReportRepository) ReportService {
+	return &reportService{repo: repo}
+}
+
+func (s *reportService) AIUsage(groupBy, from, to string) ([]dtos.AIUsageReportRow, error) {
+	start, end, err := reportPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var aggregates []repository.AIUsageAggregate
+	switch groupBy {
+	case ReportGroupByRobot, "":
+		aggregates, err = s.repo.AIUsageByRobot(start, end)
+	case ReportGroupByUser:
+		aggregates, err = s.repo.AIUsageByUser(start, end)
+	case ReportGroupByMonth:
+		// Um total por mês, inclusive os meses sem conversa, sem depender de
+		// funções de data específicas do banco
+		for month := start; month.Before(end); month = month.AddDate(0, 1, 0) {
+			total, err := s.repo.AIUsageTotal(month, month.AddDate(0, 1, 0))
+			if err != nil {
+				return nil, err
+			}
+			total.Key = month.Format(reportMonthLayout)
+			aggregates = append(aggregates, *total)
+		}
+	default:
+		return nil, ErrInvalidReportGroup
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]dtos.AIUsageReportRow, len(aggregates))
+	for i, aggregate := range aggregates {
+		rows[i] = dtos.AIUsageReportRow{
+			Key:              aggregate.Key,
+			Messages:         aggregate.Messages,
+			PromptTokens:     aggregate.PromptTokens,
+			CompletionTokens: aggregate.CompletionTokens,
+			Cost:             aggregate.Cost,
+		}
+	}
+	return rows, nil
+}
+
+// PlanRevenue coloca lado a lado o gasto com IA e a receita do Stripe de cada plano
+func (s *reportService) PlanRevenue(from, to string) ([]dtos.PlanRevenueReportRow, error) {
+	start, end, err := reportPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.repo.AIUsageByPlan(start, end)
+	if err != nil {
+		return nil, err
+	}
+	revenue, err := s.repo.RevenueByPlan(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Uma linha por plano e moeda: receitas em moedas diferentes não são somadas.
+	// O gasto com IA (sempre em USD) fica na primeira linha do plano.
+	var rows []dtos.PlanRevenueReportRow
+	byKey := make(map[string]int)
+	row := func(planType, currency string) *dtos.PlanRevenueReportRow {
+		if i, ok := byKey[planType+"|"+currency]; ok {
+			return &rows[i]
+		}
+		// A linha criada só com o gasto de IA recebe a primeira moeda do plano
+		if i, ok := byKey[planType+"|"]; ok && currency != "" {
+			delete(byKey, planType+"|")
+			byKey[planType+"|"+currency] = i
+			rows[i].Currency = currency
+			return &rows[i]
+		}
+		byKey[planType+"|"+currency] = len(rows)
+		rows = append(rows, dtos.PlanRevenueReportRow{PlanType: planType, Currency: currency})
+		return &rows[len(rows)-1]
+	}
+
+	for _, aggregate := range usage {
+		r := row(aggregate.Key, "")
+		r.Messages = aggregate.Messages
+		r.PromptTokens = aggregate.PromptTokens
+		r.CompletionTokens = aggregate.CompletionTokens
+		r.AICost = aggregate.Cost
+	}
+	for _, aggregate := range revenue {
+		r := row(aggregate.PlanType, aggregate.Currency)
+		r.Payments += aggregate.Payments
+		r.Revenue += aggregate.Revenue
+	}
+
+	return rows, nil
+}
+
+// reportPeriod converte os meses "YYYY-MM" em [início de from, início do mês seguinte a to).
+// Sem parâmetros, o relatório cobre os últimos seis meses até o mês atual.
+func reportPeriod(from, to string) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if to != "" {
+		parsed, err := time.Parse(reportMonthLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidReportPeriod
+		}
+		end = parsed
+	}
+	start := end.AddDate(0, -5, 0)
+	if from != "" {
+		parsed, err := time.Parse(reportMonthLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidReportPeriod
+		}
+		start = parsed
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, ErrInvalidReportPeriod
+	}
+	return start, end.AddDate(0, 1, 0), nil
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ErrCircuitOpen indica que o provedor falhou demais e está em pausa
+var ErrCircuitOpen = errors.New("provedor de IA temporariamente indisponível (circuit breaker aberto)")
+
+// ResilienceConfig controla timeout, retentativas e circuit breaker das chamadas de IA
+type ResilienceConfig struct {
+	Timeout          time.Duration // por tentativa
+	MaxRetries       int           // tentativas extras após a primeira
+	RetryBackoff     time.Duration // base do backoff exponencial
+	MaxRetryBackoff  time.Duration
+	BreakerThreshold int // falhas seguidas até abrir o circuito
+	BreakerCooldown  time.Duration
+}
+
+// ResilienceConfigFromEnv lê LLM_TIMEOUT_SECONDS, LLM_MAX_RETRIES, LLM_RETRY_BACKOFF_MS,
+// LLM_BREAKER_FAILURES e LLM_BREAKER_COOLDOWN_SECONDS
+func ResilienceConfigFromEnv() ResilienceConfig {
+	return ResilienceConfig{
+		Timeout:          time.Duration(envInt("LLM_TIMEOUT_SECONDS", 20)) * time.Second,
+		MaxRetries:       envInt("LLM_MAX_RETRIES", 2),
+		RetryBackoff:     time.Duration(envInt("LLM_RETRY_BACKOFF_MS", 300)) * time.Millisecond,
+		MaxRetryBackoff:  5 * time.Second,
+		BreakerThreshold: envInt("LLM_BREAKER_FAILURES", 5),
+		BreakerCooldown:  time.Duration(envInt("LLM_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second,
+	}
+}
+
+// resilientProvider envolve um LLMProvider com deadline por tentativa, retentativas
+// com jitter para erros transitórios e um circuit breaker próprio do provedor
+type resilientProvider struct {
+	provider LLMProvider
+	config   ResilienceConfig
+	breaker  *circuitBreaker
+}
+
+func NewResilientProvider(provider LLMProvider, config ResilienceConfig) LLMProvider {
+	return &resilientProvider{
+		provider: provider,
+		config:   config,
+		breaker:  &circuitBreaker{threshold: config.BreakerThreshold, cooldown: config.BreakerCooldown},
+	}
+}
+
+func (p *resilientProvider) Name() string {
+	return p.provider.Name()
+}
+
+func (p *resilientProvider) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	return p.call(ctx, func(attemptCtx context.Context) (*LLMResponse, bool, error) {
+		resp, err := p.provider.Complete(attemptCtx, req)
+		return resp, true, err
+	})
+}
+
+// Stream só é repetido enquanto nenhum pedaço foi entregue; depois disso o
+// robô já recebeu parte do texto e a falha é devolvida como está
+func (p *resilientProvider) Stream(ctx context.Context, req LLMRequest, onChunk LLMChunkHandler) (*LLMResponse, error) {
+	emitted := false
+	return p.call(ctx, func(attemptCtx context.Context) (*LLMResponse, bool, error) {
+		resp, err := p.provider.Stream(attemptCtx, req, func(chunk string) error {
+			emitted = true
+			return onChunk(chunk)
+		})
+		return resp, !emitted, err
+	})
+}
+
+// call executa attempt respeitando o breaker. attempt informa se a falha ainda pode ser repetida.
+func (p *resilientProvider) call(ctx context.Context, attempt func(ctx context.Context) (*LLMResponse, bool, error)) (*LLMResponse, error) {
+	if !p.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	var lastErr error
+	for i := 0; i <= p.config.MaxRetries; i++ {
+		if i > 0 {
+			if err := sleepContext(ctx, p.backoff(i)); err != nil {
+				p.breaker.abort()
+				return nil, err
+			}
+		}
+
+		attemptCtx, cancel := p.attemptContext(ctx)
+		resp, retryable, err := attempt(attemptCtx)
+		cancel()
+		if err == nil {
+			p.breaker.success()
+			return resp, nil
+		}
+
+		// Quem chamou desistiu (ex.: o robô desconectou): não é culpa do provedor
+		if ctx.Err() != nil {
+			p.breaker.abort()
+			return nil, err
+		}
+
+		lastErr = err
+		if !retryableLLMError(err) {
+			p.breaker.abort()
+			return nil, err
+		}
+		if !retryable {
+			break
+		}
+		log.Printf("Chamada ao provedor %s falhou (tentativa %d de %d): %v\n", p.provider.Name(), i+1, p.config.MaxRetries+1, err)
+	}
+
+	if p.breaker.failure() {
+		log.Printf("Circuit breaker do provedor %s aberto por %s\n", p.provider.Name(), p.config.BreakerCooldown)
+	}
+	return nil, fmt.Errorf("provedor %s indisponível: %w", p.provider.Name(), lastErr)
+}
+
+// attemptContext aplica o deadline de uma tentativa sobre o contexto da requisição
+func (p *resilientProvider) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.config.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.config.Timeout)
+}
+
+// backoff exponencial com full jitter: um valor aleatório entre zero e base*2^(tentativa-1)
+func (p *resilientProvider) backoff(attempt int) time.Duration {
+	limit := p.config.RetryBackoff << (attempt - 1)
+	if limit <= 0 || limit > p.config.MaxRetryBackoff {
+		limit = p.config.MaxRetryBackoff
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(limit)))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryableLLMError identifica falhas transitórias: timeout, rede, 429 e 5xx
+func retryableLLMError(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == 429 || apiErr.HTTPStatusCode >= 500
+	}
+	var requestErr *openai.RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr.HTTPStatusCode == 429 || requestErr.HTTPStatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr)
+}
+
+// circuitBreaker abre após threshold falhas seguidas. Passado o cooldown, deixa
+// uma única chamada de teste passar (meio-aberto): sucesso fecha, falha reabre.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+}
+
+// failure registra uma falha do provedor e informa se o circuito acabou de abrir
+func (b *circuitBreaker) failure() bool {
+	if b.threshold <= 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+		return true
+	}
+	return false
+}
+
+// abort encerra uma chamada sem contar sucesso nem falha (ex.: cancelada pelo cliente)
+func (b *circuitBreaker) abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
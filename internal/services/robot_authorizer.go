@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+var (
+	ErrInvalidRobotToken        = errors.New("invalid token")
+	ErrMissingRobotID           = errors.New("invalid or missing 'robo_id' in token")
+	ErrInvalidRobotIDFormat     = errors.New("invalid robot ID format")
+	ErrRobotInactive            = errors.New("robot is not active")
+	ErrRobotSubscriptionExpired = errors.New("subscription expired")
+	ErrRobotTokenRevoked        = errors.New("robot token has been revoked or replaced by a newer one")
+)
+
+// Intervalo mínimo entre gravações de LastUsedAt do mesmo token
+const robotTokenTouchInterval = time.Minute
+
+// RobotAuthorization é a identidade de um robô autorizado a usar a API
+type RobotAuthorization struct {
+	RobotID      uuid.UUID
+	TokenID      *uuid.UUID // "jti"; nil para tokens emitidos antes do registro de tokens
+	Robot        *models.Robot
+	Subscription *models.Subscription // nil para robôs legados, autorizados pelo PlanValidUntil
+}
+
+// RobotAuthorizer aplica as regras de acesso dos robôs: token válido, robô
+// existente e ativo, e assinatura ativa ou plano legado ainda válido. É usado
+// pelo RoboAuthMiddleware e para revalidar conexões longas (WebSocket).
+type RobotAuthorizer interface {
+	Authorize(token string) (*RobotAuthorization, error)
+	// AuthorizeRobot aplica só as regras do robô e da assinatura, para checar
+	// antes de emitir um token
+	AuthorizeRobot(robotID uuid.UUID) (*RobotAuthorization, error)
+}
+
+type robotAuthorizer struct {
+	authService      AuthService
+	subscriptionRepo repository.SubscriptionRepository
+	robotRepo        repository.RobotRepository
+	tokenRepo        repository.RobotTokenRepository
+}
+
+func NewRobotAuthorizer(authService AuthService, subscriptionRepo repository.SubscriptionRepository, robotRepo repository.RobotRepository, tokenRepo repository.RobotTokenRepository) RobotAuthorizer {
+	return &robotAuthorizer{
+		authService:      authService,
+		subscriptionRepo: subscriptionRepo,
+		robotRepo:        robotRepo,
+		tokenRepo:        tokenRepo,
+	}
+}
+
+func (a *robotAuthorizer) Authorize(token string) (*RobotAuthorization, error) {
+	claims, err := a.authService.VerifyToken(token)
+	if err != nil {
+		return nil, ErrInvalidRobotToken
+	}
+
+	roboIDStr, ok := claims["robo_id"].(string)
+	if !ok {
+		return nil, ErrMissingRobotID
+	}
+	roboID, err := uuid.Parse(roboIDStr)
+	if err != nil {
+		return nil, ErrInvalidRobotIDFormat
+	}
+
+	tokenID, err := a.checkTokenID(claims["jti"], roboID)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization, err := a.AuthorizeRobot(roboID)
+	if err != nil {
+		return nil, err
+	}
+	authorization.TokenID = tokenID
+	return authorization, nil
+}
+
+func (a *robotAuthorizer) AuthorizeRobot(robotID uuid.UUID) (*RobotAuthorization, error) {
+	robot, err := a.robotRepo.FindById(robotID)
+	if err != nil || robot == nil {
+		return nil, ErrRobotNotFound
+	}
+	if robot.Status != "active" {
+		return nil, ErrRobotInactive
+	}
+
+	subscription, err := a.subscriptionRepo.FindActiveByRobotID(robotID)
+	if err != nil || subscription == nil || !subscription.IsActive() {
+		// Sem assinatura ativa, vale o período de validade do plano legado
+		if robot.PlanValidUntil == nil || robot.PlanValidUntil.Before(time.Now()) {
+			return nil, ErrRobotSubscriptionExpired
+		}
+	}
+
+	return &RobotAuthorization{RobotID: robotID, Robot: robot, Subscription: subscription}, nil
+}
+
+// checkTokenID confere o "jti" no registro de tokens: só vale o token mais
+// recente e não revogado do robô. Tokens antigos, sem jti, continuam valendo
+// até o robô receber o primeiro token registrado.
+func (a *robotAuthorizer) checkTokenID(claim interface{}, robotID uuid.UUID) (*uuid.UUID, error) {
+	if claim == nil {
+		count, err := a.tokenRepo.CountByRobotID(robotID)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrRobotTokenRevoked
+		}
+		return nil, nil
+	}
+
+	jti, _ := claim.(string)
+	tokenID, err := uuid.Parse(jti)
+	if err != nil {
+		return nil, ErrInvalidRobotToken
+	}
+	token, err := a.tokenRepo.FindByID(tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token == nil || token.RobotID != robotID || !token.Active(now) {
+		return nil, ErrRobotTokenRevoked
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > robotTokenTouchInterval {
+		if err := a.tokenRepo.TouchLastUsed(token.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	return &token.ID, nil
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+var (
+	ErrInvalidCommandType    = errors.New("command type must be say, dance or reboot")
+	ErrInvalidCommandPayload = errors.New("say commands need a non-empty payload.text with up to 500 characters")
+	ErrCommandNotFound       = errors.New("command not found")
+	ErrCommandNotAckable     = errors.New("command is not waiting for an acknowledgement")
+)
+
+const (
+	commandBatchSize = 20
+	// Maior espera aceita no long-polling
+	commandMaxWait = 60 * time.Second
+	// Releitura do banco durante a espera, para comandos criados por outra instância
+	commandPollInterval = 5 * time.Second
+)
+
+// RobotCommandService cuida da fila de comandos do dono para o robô. A entrega
+// é "pelo menos uma vez": um comando entregue e não confirmado dentro de
+// ackTimeout volta a ser entregue, então o robô deve ignorar IDs repetidos.
+type RobotCommandService interface {
+	Create(robotID, userID string, input dtos.CreateRobotCommandInputDTO) (*models.RobotCommand, error)
+	FindAllForOwner(robotID, userID string, status models.CommandStatus) ([]models.RobotCommand, error)
+	FindForOwner(robotID, commandID, userID string) (*models.RobotCommand, error)
+	// Poll espera até wait por comandos do robô e os marca como entregues
+	Poll(ctx context.Context, robotID uuid.UUID, wait time.Duration) ([]models.RobotCommand, error)
+	Ack(robotID uuid.UUID, commandID string, input dtos.AckRobotCommandInputDTO) (*models.RobotCommand, error)
+	// PushPending entrega os comandos pendentes pelo canal em tempo real, se o robô estiver conectado
+	PushPending(robotID uuid.UUID) (int, error)
+	ExpirePending() (int64, error)
+	RunExpirySweeper(ctx context.Context, interval time.Duration)
+}
+
+type robotCommandService struct {
+	repo       repository.RobotCommandRepository
+	robotRepo  repository.RobotRepository
+	hub        RobotHub
+	defaultTTL time.Duration
+	ackTimeout time.Duration
+
+	mu      sync.Mutex
+	waiters map[uuid.UUID]chan struct{}
+}
+
+func NewRobotCommandService(repo repository.RobotCommandRepository, robotRepo repository.RobotRepository, hub RobotHub) RobotCommandService {
+	return &robotCommandService{
+		repo:       repo,
+		robotRepo:  robotRepo,
+		hub:        hub,
+		defaultTTL: time.Duration(envInt("COMMAND_DEFAULT_TTL_SECONDS", 300)) * time.Second,
+		ackTimeout: time.Duration(envInt("COMMAND_ACK_TIMEOUT_SECONDS", 30)) * time.Second,
+		waiters:    make(map[uuid.UUID]chan struct{}),
+	}
+}
+
+func (s *robotCommandService) Create(robotID, userID string, input dtos.CreateRobotCommandInputDTO) (*models.RobotCommand, error) {
+	commandType := models.CommandType(strings.ToLower(strings.TrimSpace(input.Type)))
+	if err := validateCommand(commandType, input.Payload); err != nil {
+		return nil, err
+	}
+
+	robot, err := s.robotRepo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+
+	ttl := s.defaultTTL
+	if input.TTLSeconds > 0 {
+		ttl = time.Duration(input.TTLSeconds) * time.Second
+	}
+
+	command := &models.RobotCommand{
+		RobotID:   robot.ID,
+		UserID:    robot.UserID,
+		Type:      commandType,
+		Payload:   input.Payload,
+		Status:    models.CommandQueued,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.repo.Create(command); err != nil {
+		return nil, err
+	}
+
+	s.notify(robot.ID)
+	if _, err := s.PushPending(robot.ID); err != nil {
+		// O comando continua na fila para o long-polling
+		log.Printf("Erro ao enviar comandos pelo canal em tempo real do robô %s: %v\n", robot.ID, err)
+	}
+	return command, nil
+}
+
+func validateCommand(commandType models.CommandType, payload map[string]interface{}) error {
+	switch commandType {
+	case models.CommandSay:
+		text, _ := payload["text"].(string)
+		if strings.TrimSpace(text) == "" || len([]rune(text)) > 500 {
+			return ErrInvalidCommandPayload
+		}
+	case models.CommandDance, models.CommandReboot:
+	default:
+		return ErrInvalidCommandType
+	}
+	return nil
+}
+
+// FindAllForOwner lista os últimos 100 comandos de um robô do usuário
+func (s *robotCommandService) FindAllForOwner(robotID, userID string, status models.CommandStatus) ([]models.RobotCommand, error) {
+	robot, err := s.robotRepo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+	return s.repo.FindByRobotID(robot.ID, status, 100)
+}
+
+func (s *robotCommandService) FindForOwner(robotID, commandID, userID string) (*models.RobotCommand, error) {
+	robot, err := s.robotRepo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+
+	id, err := uuid.Parse(commandID)
+	if err != nil {
+		return nil, ErrCommandNotFound
+	}
+	command, err := s.repo.FindByIDAndRobotID(id, robot.ID)
+	if err != nil {
+		return nil, err
+	}
+	if command == nil {
+		return nil, ErrCommandNotFound
+	}
+	return command, nil
+}
+
+func (s *robotCommandService) Poll(ctx context.Context, robotID uuid.UUID, wait time.Duration) ([]models.RobotCommand, error) {
+	if wait > commandMaxWait {
+		wait = commandMaxWait
+	}
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+
+	for {
+		// Inscreve antes de consultar para não perder um comando criado no meio
+		wake := s.subscribe(robotID)
+
+		commands, err := s.claim(robotID)
+		if err != nil || len(commands) > 0 || wait <= 0 {
+			return commands, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return []models.RobotCommand{}, nil
+		case <-deadline.C:
+			return []models.RobotCommand{}, nil
+		case <-wake:
+		case <-time.After(commandPollInterval):
+		}
+	}
+}
+
+// claim marca como entregues os comandos pendentes do robô, ignorando os que
+// uma requisição concorrente pegou primeiro
+func (s *robotCommandService) claim(robotID uuid.UUID) ([]models.RobotCommand, error) {
+	now := time.Now()
+	redeliverBefore := now.Add(-s.ackTimeout)
+
+	pending, err := s.repo.FindDeliverable(robotID, now, redeliverBefore, commandBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]models.RobotCommand, 0, len(pending))
+	for _, command := range pending {
+		ok, err := s.repo.MarkDelivered(command.ID, now, redeliverBefore)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		command.Status = models.CommandDelivered
+		command.DeliveredAt = &now
+		claimed = append(claimed, command)
+	}
+	return claimed, nil
+}
+
+// Ack registra o resultado informado pelo robô para um comando entregue
+func (s *robotCommandService) Ack(robotID uuid.UUID, commandID string, input dtos.AckRobotCommandInputDTO) (*models.RobotCommand, error) {
+	id, err := uuid.Parse(commandID)
+	if err != nil {
+		return nil, ErrCommandNotFound
+	}
+
+	ok, err := s.repo.Complete(id, robotID, models.CommandStatus(input.Status), input.Result)
+	if err != nil {
+		return nil, err
+	}
+
+	command, err := s.repo.FindByIDAndRobotID(id, robotID)
+	if err != nil {
+		return nil, err
+	}
+	if command == nil {
+		return nil, ErrCommandNotFound
+	}
+	// Confirmação repetida (a resposta anterior se perdeu) devolve o mesmo resultado
+	if !ok && command.Status != models.CommandStatus(input.Status) {
+		return nil, ErrCommandNotAckable
+	}
+	return command, nil
+}
+
+func (s *robotCommandService) PushPending(robotID uuid.UUID) (int, error) {
+	if !s.hub.Connected(robotID) {
+		return 0, nil
+	}
+
+	commands, err := s.claim(robotID)
+	if err != nil {
+		return 0, err
+	}
+	// Um comando que não chegar (a conexão caiu agora) é reentregue após ackTimeout
+	for _, command := range commands {
+		s.hub.Send(robotID, dtos.NewRobotChannelMessage(dtos.ChannelCommand, command))
+	}
+	return len(commands), nil
+}
+
+// ExpirePending marca como failed os comandos cujo TTL venceu antes da confirmação
+func (s *robotCommandService) ExpirePending() (int64, error) {
+	return s.repo.ExpirePending(time.Now())
+}
+
+// RunExpirySweeper executa ExpirePending periodicamente até o contexto ser cancelado
+func (s *robotCommandService) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if expired, err := s.ExpirePending(); err != nil {
+			log.Printf("Erro ao expirar comandos dos robôs: %v\n", err)
+		} else if expired > 0 {
+			log.Printf("%d comando(s) de robô expirado(s)\n", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// subscribe retorna um canal fechado no próximo comando criado para o robô
+func (s *robotCommandService) subscribe(robotID uuid.UUID) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wake, ok := s.waiters[robotID]
+	if !ok {
+		wake = make(chan struct{})
+		s.waiters[robotID] = wake
+	}
+	return wake
+}
+
+// notify acorda todos os long-polls em espera do robô
+func (s *robotCommandService) notify(robotID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if wake, ok := s.waiters[robotID]; ok {
+		close(wake)
+		delete(s.waiters, robotID)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+)
+
+var ErrRobotNotConnected = errors.New("robot is not connected to the real-time channel")
+
+// Mensagens pendentes por conexão; uma conexão que não acompanha é derrubada
+const robotConnectionBuffer = 32
+
+// RobotChannelConfig controla o keepalive e a revalidação do token das conexões
+type RobotChannelConfig struct {
+	PingInterval    time.Duration
+	RecheckInterval time.Duration
+}
+
+// RobotChannelConfigFromEnv lê ROBOT_WS_PING_SECONDS e ROBOT_WS_RECHECK_SECONDS
+func RobotChannelConfigFromEnv() RobotChannelConfig {
+	ping := envInt("ROBOT_WS_PING_SECONDS", 30)
+	if ping < 1 {
+		ping = 30
+	}
+	recheck := envInt("ROBOT_WS_RECHECK_SECONDS", 60)
+	if recheck < 1 {
+		recheck = 60
+	}
+	return RobotChannelConfig{
+		PingInterval:    time.Duration(ping) * time.Second,
+		RecheckInterval: time.Duration(recheck) * time.Second,
+	}
+}
+
+// RobotConnection é uma conexão em tempo real de um robô registrada no hub
+type RobotConnection struct {
+	RobotID   uuid.UUID
+	send      chan dtos.RobotChannelMessage
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Messages entrega as mensagens a escrever na conexão
+func (c *RobotConnection) Messages() <-chan dtos.RobotChannelMessage {
+	return c.send
+}
+
+// Done é fechado quando o hub derruba a conexão
+func (c *RobotConnection) Done() <-chan struct{} {
+	return c.done
+}
+
+// Push enfileira uma mensagem sem bloquear; retorna false se o buffer está cheio
+func (c *RobotConnection) Push(message dtos.RobotChannelMessage) bool {
+	select {
+	case <-c.done:
+		return false
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *RobotConnection) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// RobotHub é o registro das conexões em tempo real, com fan-out para todas as
+// conexões de um mesmo robô. Vale só para esta instância do servidor.
+type RobotHub interface {
+	Register(robotID uuid.UUID) *RobotConnection
+	Unregister(conn *RobotConnection)
+	// Send retorna quantas conexões do robô receberam a mensagem
+	Send(robotID uuid.UUID, message dtos.RobotChannelMessage) int
+	Connected(robotID uuid.UUID) bool
+}
+
+type robotHub struct {
+	mu          sync.RWMutex
+	connections map[uuid.UUID]map[*RobotConnection]struct{}
+}
+
+func NewRobotHub() RobotHub {
+	return &robotHub{connections: make(map[uuid.UUID]map[*RobotConnection]struct{})}
+}
+
+func (h *robotHub) Register(robotID uuid.UUID) *RobotConnection {
+	conn := &RobotConnection{
+		RobotID: robotID,
+		send:    make(chan dtos.RobotChannelMessage, robotConnectionBuffer),
+		done:    make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.connections[robotID] == nil {
+		h.connections[robotID] = make(map[*RobotConnection]struct{})
+	}
+	h.connections[robotID][conn] = struct{}{}
+	return conn
+}
+
+func (h *robotHub) Unregister(conn *RobotConnection) {
+	conn.close()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.connections[conn.RobotID], conn)
+	if len(h.connections[conn.RobotID]) == 0 {
+		delete(h.connections, conn.RobotID)
+	}
+}
+
+func (h *robotHub) Send(robotID uuid.UUID, message dtos.RobotChannelMessage) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	sent := 0
+	for conn := range h.connections[robotID] {
+		if conn.Push(message) {
+			sent++
+			continue
+		}
+		log.Printf("Conexão em tempo real do robô %s não acompanha as mensagens; encerrando\n", robotID)
+		conn.close()
+	}
+	return sent
+}
+
+func (h *robotHub) Connected(robotID uuid.UUID) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.connections[robotID]) > 0
+}
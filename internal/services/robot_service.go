@@ -3,15 +3,25 @@ package services
 import (
 	"errors"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
 	"github.com/peruccii/roadmap-go-backend/internal/models"
 	"github.com/peruccii/roadmap-go-backend/internal/repository"
 )
 
+var (
+	ErrInvalidDisplayURL  = errors.New("display_url must be a public http(s) URL")
+	ErrRobotTokenNotFound = errors.New("robot token not found")
+)
+
+// Validade dos tokens de robô
+const robotTokenTTL = 30 * 24 * time.Hour
+
 type CreateRobotInput struct {
 	Name   string
 	UserID string
@@ -20,10 +30,12 @@ type CreateRobotInput struct {
 type robotService struct {
 	repo        repository.RobotRepository
 	planService PlanService
+	hub         RobotHub
+	tokenRepo   repository.RobotTokenRepository
 	secretKey   []byte
 }
 
-func NewRobotService(repo repository.RobotRepository, planService PlanService) RobotService {
+func NewRobotService(repo repository.RobotRepository, planService PlanService, hub RobotHub, tokenRepo repository.RobotTokenRepository) RobotService {
 	secret := os.Getenv("JWT_SECRET_KEY")
 	if secret == "" {
 		secret = "default-secret"
@@ -31,6 +43,8 @@ func NewRobotService(repo repository.RobotRepository, planService PlanService) R
 	return &robotService{
 		repo:        repo,
 		planService: planService,
+		hub:         hub,
+		tokenRepo:   tokenRepo,
 		secretKey:   []byte(secret),
 	}
 }
@@ -39,7 +53,14 @@ type RobotService interface {
 	CreateRobot(input CreateRobotInput) error
 	FindByName(name string) (*models.Robot, error)
 	GenerateRobotToken(robotID, userID string) (string, error)
+	// IssueToken assina um token do robô sem checar dono nem plano (quem chama já
+	// validou). O novo token revoga os anteriores do robô.
+	IssueToken(robotID uuid.UUID, source models.RobotTokenSource) (string, error)
+	FindTokens(robotID, userID string) ([]models.RobotToken, error)
+	RevokeToken(robotID, tokenID, userID string) (*models.RobotToken, error)
 	FindAll() ([]models.Robot, error)
+	UpdateSettings(robotID, userID string, input dtos.UpdateRobotSettingsInputDTO) (*models.Robot, error)
+	Speak(robotID, userID string, input dtos.PushSpeechInputDTO) (int, error)
 }
 
 func (r *robotService) FindAll() ([]models.Robot, error) {
@@ -79,19 +100,130 @@ func (s *robotService) GenerateRobotToken(robotID, userID string) (string, error
 		return "", errors.New("plan expired")
 	}
 
+	return s.IssueToken(robot.ID, models.TokenFromOwner)
+}
+
+// IssueToken registra o token (o ID do registro vira o "jti") antes de assiná-lo
+func (s *robotService) IssueToken(robotID uuid.UUID, source models.RobotTokenSource) (string, error) {
+	record := &models.RobotToken{
+		RobotID:   robotID,
+		Source:    source,
+		ExpiresAt: time.Now().Add(robotTokenTTL),
+	}
+	if err := s.tokenRepo.CreateSuperseding(record); err != nil {
+		return "", err
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
 		jwt.MapClaims{
-			"robo_id": robot.ID,
-			"exp":     time.Now().Add(time.Hour * 24 * 30).Unix(),
+			"robo_id": robotID,
+			"jti":     record.ID.String(),
+			"exp":     record.ExpiresAt.Unix(),
 		})
 
 	return token.SignedString(s.secretKey)
 }
 
+// FindTokens lista os últimos 50 tokens emitidos para um robô do usuário
+func (s *robotService) FindTokens(robotID, userID string) ([]models.RobotToken, error) {
+	robot, err := s.repo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+	return s.tokenRepo.FindByRobotID(robot.ID, 50)
+}
+
+// RevokeToken revoga um token de um robô do usuário. Revogar de novo não é erro.
+func (s *robotService) RevokeToken(robotID, tokenID, userID string) (*models.RobotToken, error) {
+	robot, err := s.repo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+
+	id, err := uuid.Parse(tokenID)
+	if err != nil {
+		return nil, ErrRobotTokenNotFound
+	}
+	token, err := s.tokenRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || token.RobotID != robot.ID {
+		return nil, ErrRobotTokenNotFound
+	}
+
+	if _, err := s.tokenRepo.Revoke(token.ID, models.TokenRevokedByOwner); err != nil {
+		return nil, err
+	}
+	return s.tokenRepo.FindByID(token.ID)
+}
+
 func (r *robotService) FindByName(name string) (*models.Robot, error) {
 	return r.repo.FindByName(name)
 }
 
+// UpdateSettings altera as configurações de hardware de um robô do usuário.
+// display_url vazio volta a usar o servidor de display padrão.
+func (r *robotService) UpdateSettings(robotID, userID string, input dtos.UpdateRobotSettingsInputDTO) (*models.Robot, error) {
+	robot, err := r.repo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+
+	if input.DisplayURL != nil {
+		displayURL := strings.TrimSpace(*input.DisplayURL)
+		if displayURL != "" {
+			if err := validateDisplayURL(displayURL); err != nil {
+				return nil, err
+			}
+		}
+		robot.DisplayURL = displayURL
+	}
+	if input.HardwareModel != nil {
+		robot.HardwareModel = strings.TrimSpace(*input.HardwareModel)
+	}
+
+	if err := r.repo.UpdateSettings(robot); err != nil {
+		return nil, err
+	}
+
+	r.hub.Send(robot.ID, dtos.NewRobotChannelMessage(dtos.ChannelConfig, dtos.ChannelConfigData{
+		DisplayURL:    robot.DisplayURL,
+		HardwareModel: robot.HardwareModel,
+	}))
+	return robot, nil
+}
+
+// Speak envia uma fala proativa ao robô pelo canal em tempo real. Não fica em
+// fila: se o robô não estiver conectado, retorna ErrRobotNotConnected.
+func (r *robotService) Speak(robotID, userID string, input dtos.PushSpeechInputDTO) (int, error) {
+	robot, err := r.repo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if robot == nil {
+		return 0, ErrRobotNotFound
+	}
+
+	sent := r.hub.Send(robot.ID, dtos.NewRobotChannelMessage(dtos.ChannelSpeech, dtos.ChannelSpeechData{
+		Text:    input.Text,
+		Emotion: input.Emotion,
+	}))
+	if sent == 0 {
+		return 0, ErrRobotNotConnected
+	}
+	return sent, nil
+}
+
 // CreateRobot agora não pode criar robô diretamente - deve ser feito através do pagamento
 func (r *robotService) CreateRobot(input CreateRobotInput) error {
 	return errors.New("criação de robô deve ser feita através do pagamento. Use o endpoint de pagamento")
@@ -2,9 +2,12 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,34 +16,62 @@ import (
 	"github.com/stripe/stripe-go/v82"
 	"github.com/stripe/stripe-go/v82/checkout/session"
 	"github.com/stripe/stripe-go/v82/customer"
-	"github.com/stripe/stripe-go/v82/price"
-	"github.com/stripe/stripe-go/v82/product"
-	"github.com/stripe/stripe-go/v82/sub"
+	sub "github.com/stripe/stripe-go/v82/subscription"
+	"github.com/stripe/stripe-go/v82/webhook"
 )
 
+var (
+	ErrWebhookSecretNotConfigured = errors.New("stripe webhook secret is not configured")
+	ErrWebhookSignatureMissing    = errors.New("missing or malformed Stripe-Signature header")
+	ErrWebhookSignatureInvalid    = errors.New("invalid Stripe webhook signature")
+	ErrWebhookPayloadInvalid      = errors.New("invalid Stripe webhook payload")
+	ErrStripeEventNotFound        = errors.New("stripe event not found")
+	ErrStripeEventInProgress      = errors.New("stripe event is being processed by another delivery")
+)
+
+// Tempo máximo de um evento em "processing" antes de ser considerado abandonado
+const stripeEventLease = 5 * time.Minute
+
 type StripeProvider struct {
-	SecretKey        string
-	paymentRepo      repository.PaymentRepository
-	subscriptionRepo repository.SubscriptionRepository
-	robotRepo        repository.RobotRepository
-	paymentService   PaymentService
+	SecretKey           string
+	webhookSecrets      []string
+	webhookTolerance    time.Duration
+	paymentRepo         repository.PaymentRepository
+	subscriptionRepo    repository.SubscriptionRepository
+	robotRepo           repository.RobotRepository
+	eventRepo           repository.StripeEventRepository
+	paymentService      PaymentService
+	subscriptionService SubscriptionService
+	planCatalogService  PlanCatalogService
 }
 
 type StripeService interface {
 	CreateCustomer(name, email string) (*stripe.Customer, error)
+	ConstructWebhookEvent(payload []byte, signatureHeader string) (stripe.Event, error)
 	HandleEvents(event stripe.Event) error
+	ProcessWebhookEvent(event stripe.Event, payload []byte) error
+	ReplayEvent(eventID string) (*models.StripeEvent, error)
+	ListEvents(status models.StripeEventStatus) ([]models.StripeEvent, error)
+	FindEvent(eventID string) (*models.StripeEvent, error)
 	CreateCheckoutSessionForRobot(userID, robotName, planType string, userEmail string) (*stripe.CheckoutSession, error)
 	CreateSubscription(customerID, priceID string, robotID uuid.UUID) (*stripe.Subscription, error)
-	CancelSubscription(subscriptionID string) error
+	CancelSubscription(subscription *models.Subscription) error
+	ResumeSubscription(subscription *models.Subscription) error
+	ChangeSubscriptionPlan(subscription *models.Subscription, planType string) error
 }
 
-func NewStripeService(paymentRepo repository.PaymentRepository, subscriptionRepo repository.SubscriptionRepository, robotRepo repository.RobotRepository, paymentService PaymentService) StripeService {
+func NewStripeService(paymentRepo repository.PaymentRepository, subscriptionRepo repository.SubscriptionRepository, robotRepo repository.RobotRepository, eventRepo repository.StripeEventRepository, paymentService PaymentService, subscriptionService SubscriptionService, planCatalogService PlanCatalogService) StripeService {
 	return &StripeProvider{
-		SecretKey:        os.Getenv("STRIPE_SECRET_KEY"),
-		paymentRepo:      paymentRepo,
-		subscriptionRepo: subscriptionRepo,
-		robotRepo:        robotRepo,
-		paymentService:   paymentService,
+		SecretKey:           os.Getenv("STRIPE_SECRET_KEY"),
+		webhookSecrets:      parseWebhookSecrets(os.Getenv("STRIPE_WEBHOOK_SECRET")),
+		webhookTolerance:    parseWebhookTolerance(os.Getenv("STRIPE_WEBHOOK_TOLERANCE")),
+		paymentRepo:         paymentRepo,
+		subscriptionRepo:    subscriptionRepo,
+		robotRepo:           robotRepo,
+		eventRepo:           eventRepo,
+		paymentService:      paymentService,
+		subscriptionService: subscriptionService,
+		planCatalogService:  planCatalogService,
 	}
 }
 
@@ -60,10 +91,10 @@ func (s *StripeProvider) CreateCustomer(name, email string) (*stripe.Customer, e
 	return result, nil
 }
 
-func (s *StripeProvider) CreateCheckoutSession() (*stripe.CheckoutSession, error) {
+func (s *StripeProvider) CreateCheckoutSession(name, email string) (*stripe.CheckoutSession, error) {
 	stripe.Key = s.SecretKey
 
-	customer, err := s.CreateCustomer()
+	customer, err := s.CreateCustomer(name, email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create customer: %w", err)
 	}
@@ -99,6 +130,124 @@ func (s *StripeProvider) CreateCheckoutSession() (*stripe.CheckoutSession, error
 	return result, nil
 }
 
+// ConstructWebhookEvent valida o header Stripe-Signature contra os secrets
+// configurados e só então decodifica o evento. Durante a rotação de secrets
+// o Stripe assina o payload com todos os secrets ativos, então basta que
+// um deles confira.
+func (s *StripeProvider) ConstructWebhookEvent(payload []byte, signatureHeader string) (stripe.Event, error) {
+	if len(s.webhookSecrets) == 0 {
+		return stripe.Event{}, ErrWebhookSecretNotConfigured
+	}
+
+	var lastErr error
+	for _, secret := range s.webhookSecrets {
+		lastErr = webhook.ValidatePayloadWithTolerance(payload, signatureHeader, secret, s.webhookTolerance)
+		if lastErr == nil {
+			break
+		}
+		if errors.Is(lastErr, webhook.ErrNotSigned) || errors.Is(lastErr, webhook.ErrInvalidHeader) {
+			return stripe.Event{}, fmt.Errorf("%w: %v", ErrWebhookSignatureMissing, lastErr)
+		}
+	}
+	if lastErr != nil {
+		return stripe.Event{}, fmt.Errorf("%w: %v", ErrWebhookSignatureInvalid, lastErr)
+	}
+
+	var event stripe.Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return stripe.Event{}, fmt.Errorf("%w: %v", ErrWebhookPayloadInvalid, err)
+	}
+
+	return event, nil
+}
+
+// ProcessWebhookEvent persiste o evento e o processa uma única vez. Entregas
+// repetidas de um evento já processado (ou em processamento) são ignoradas;
+// se o handler falhar o evento fica como "failed" e o próximo reenvio do
+// Stripe (ou um replay manual) tenta novamente.
+func (s *StripeProvider) ProcessWebhookEvent(event stripe.Event, payload []byte) error {
+	record := &models.StripeEvent{
+		ID:      event.ID,
+		Type:    string(event.Type),
+		Payload: string(payload),
+		Status:  models.StripeEventPending,
+	}
+	if err := s.eventRepo.CreateIfNotExists(record); err != nil {
+		return fmt.Errorf("erro ao registrar evento %s: %w", event.ID, err)
+	}
+
+	claimed, err := s.eventRepo.Claim(event.ID, false, time.Now().Add(-stripeEventLease))
+	if err != nil {
+		return fmt.Errorf("erro ao reservar evento %s: %w", event.ID, err)
+	}
+	if !claimed {
+		log.Printf("Evento duplicado ignorado: %s (%s)\n", event.ID, event.Type)
+		return nil
+	}
+
+	return s.runEvent(event)
+}
+
+// ReplayEvent reprocessa um evento armazenado, processado ou não. Um evento em
+// processamento por outra entrega (lease ainda válido) não é reprocessado.
+func (s *StripeProvider) ReplayEvent(eventID string) (*models.StripeEvent, error) {
+	record, err := s.eventRepo.FindByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrStripeEventNotFound
+	}
+
+	var event stripe.Event
+	if err := json.Unmarshal([]byte(record.Payload), &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebhookPayloadInvalid, err)
+	}
+
+	claimed, err := s.eventRepo.Claim(eventID, true, time.Now().Add(-stripeEventLease))
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return record, ErrStripeEventInProgress
+	}
+
+	runErr := s.runEvent(event)
+
+	record, err = s.eventRepo.FindByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	return record, runErr
+}
+
+func (s *StripeProvider) ListEvents(status models.StripeEventStatus) ([]models.StripeEvent, error) {
+	return s.eventRepo.FindAll(status)
+}
+
+func (s *StripeProvider) FindEvent(eventID string) (*models.StripeEvent, error) {
+	record, err := s.eventRepo.FindByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrStripeEventNotFound
+	}
+	return record, nil
+}
+
+// runEvent executa o handler do evento e registra o resultado
+func (s *StripeProvider) runEvent(event stripe.Event) error {
+	if err := s.HandleEvents(event); err != nil {
+		if markErr := s.eventRepo.MarkFailed(event.ID, err); markErr != nil {
+			log.Printf("Erro ao marcar evento %s como falho: %v\n", event.ID, markErr)
+		}
+		return err
+	}
+
+	return s.eventRepo.MarkProcessed(event.ID)
+}
+
 func (s *StripeProvider) HandleEvents(event stripe.Event) error {
 	stripe.Key = s.SecretKey
 
@@ -126,16 +275,9 @@ func (s *StripeProvider) HandleEvents(event stripe.Event) error {
 func (s *StripeProvider) CreateCheckoutSessionForRobot(userID, robotName, planType string, userEmail string) (*stripe.CheckoutSession, error) {
 	stripe.Key = s.SecretKey
 
-	// Mapear tipos de plano para preços do Stripe
-	priceMap := map[string]string{
-		"basic":      os.Getenv("STRIPE_BASIC_PRICE_ID"),
-		"premium":    os.Getenv("STRIPE_PREMIUM_PRICE_ID"),
-		"enterprise": os.Getenv("STRIPE_ENTERPRISE_PRICE_ID"),
-	}
-
-	priceID, exists := priceMap[planType]
-	if !exists {
-		return nil, fmt.Errorf("plano inválido: %s", planType)
+	plan, err := s.planCatalogService.FindActiveByCode(planType)
+	if err != nil {
+		return nil, err
 	}
 
 	params := &stripe.CheckoutSessionParams{
@@ -149,7 +291,7 @@ func (s *StripeProvider) CreateCheckoutSessionForRobot(userID, robotName, planTy
 		}),
 		LineItems: []*stripe.CheckoutSessionLineItemParams{
 			{
-				Price:    stripe.String(priceID),
+				Price:    stripe.String(plan.StripePriceID),
 				Quantity: stripe.Int64(1),
 			},
 		},
@@ -168,8 +310,8 @@ func (s *StripeProvider) CreateCheckoutSessionForRobot(userID, robotName, planTy
 	userUUID, _ := uuid.Parse(userID)
 	payment := &models.Payment{
 		UserID:            userUUID,
-		Amount:            s.getPlanAmount(planType),
-		Currency:          "BRL",
+		Amount:            plan.Amount,
+		Currency:          plan.Currency,
 		Status:            models.PaymentPending,
 		Provider:          models.ProviderStripe,
 		ProviderSessionID: result.ID,
@@ -204,15 +346,85 @@ func (s *StripeProvider) CreateSubscription(customerID, priceID string, robotID
 	return result, nil
 }
 
-func (s *StripeProvider) CancelSubscription(subscriptionID string) error {
-	stripe.Key = s.SecretKey
+// CancelSubscription agenda o cancelamento da assinatura para o fim do
+// período atual; o robô segue funcionando até lá.
+func (s *StripeProvider) CancelSubscription(subscription *models.Subscription) error {
+	if subscription.Status == models.SubscriptionCanceled || subscription.CancelAtPeriodEnd {
+		return ErrSubscriptionNotCancelable
+	}
 
-	params := &stripe.SubscriptionParams{
+	return s.updateStripeSubscription(subscription, &stripe.SubscriptionParams{
 		CancelAtPeriodEnd: stripe.Bool(true),
+	})
+}
+
+// ResumeSubscription desfaz um cancelamento agendado
+func (s *StripeProvider) ResumeSubscription(subscription *models.Subscription) error {
+	if subscription.Status == models.SubscriptionCanceled || !subscription.CancelAtPeriodEnd {
+		return ErrSubscriptionNotResumable
 	}
 
-	_, err := sub.Update(subscriptionID, params)
-	return err
+	return s.updateStripeSubscription(subscription, &stripe.SubscriptionParams{
+		CancelAtPeriodEnd: stripe.Bool(false),
+	})
+}
+
+// ChangeSubscriptionPlan troca o preço da assinatura (upgrade ou downgrade),
+// com a diferença proporcional cobrada ou creditada na próxima fatura.
+func (s *StripeProvider) ChangeSubscriptionPlan(subscription *models.Subscription, planType string) error {
+	plan, err := s.planCatalogService.FindActiveByCode(planType)
+	if err != nil {
+		return err
+	}
+	if subscription.Status != models.SubscriptionActive {
+		return ErrSubscriptionInactive
+	}
+	if subscription.PlanType == models.PlanType(planType) {
+		return ErrSamePlan
+	}
+
+	stripe.Key = s.SecretKey
+	current, err := sub.Get(subscription.ProviderSubscriptionID, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar assinatura no Stripe: %w", err)
+	}
+	if current.Items == nil || len(current.Items.Data) == 0 {
+		return fmt.Errorf("assinatura sem itens no Stripe: %s", subscription.ProviderSubscriptionID)
+	}
+
+	if err := s.updateStripeSubscription(subscription, &stripe.SubscriptionParams{
+		Items: []*stripe.SubscriptionItemsParams{
+			{
+				ID:    stripe.String(current.Items.Data[0].ID),
+				Price: stripe.String(plan.StripePriceID),
+			},
+		},
+		ProrationBehavior: stripe.String("create_prorations"),
+	}); err != nil {
+		return err
+	}
+
+	// Os limites do robô derivam do tipo de plano da assinatura
+	if subscription.PlanType != models.PlanType(planType) {
+		subscription.PlanType = models.PlanType(planType)
+		return s.subscriptionRepo.Update(subscription)
+	}
+
+	return nil
+}
+
+// updateStripeSubscription aplica a alteração no Stripe e, com a resposta
+// confirmada, atualiza a assinatura local
+func (s *StripeProvider) updateStripeSubscription(subscription *models.Subscription, params *stripe.SubscriptionParams) error {
+	stripe.Key = s.SecretKey
+
+	updated, err := sub.Update(subscription.ProviderSubscriptionID, params)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar assinatura no Stripe: %w", err)
+	}
+
+	s.applyStripeSubscription(subscription, updated)
+	return s.subscriptionRepo.Update(subscription)
 }
 
 func (s *StripeProvider) handleCheckoutSessionCompleted(event stripe.Event) error {
@@ -246,27 +458,36 @@ func (s *StripeProvider) handleCheckoutSessionCompleted(event stripe.Event) erro
 					UserID: payment.UserID,
 					Status: models.StatusActive,
 				}
-				if err := s.robotRepo.Create(robot); err != nil {
-					return err
+				// Robô e vínculo no pagamento são gravados juntos: num reenvio
+				// o pagamento já tem robô e cai no ramo abaixo
+				if err := s.robotRepo.CreateForPayment(robot, payment.ID); err != nil {
+					return fmt.Errorf("erro ao criar robô do pagamento %s: %w", payment.ID, err)
 				}
 				robotID = robot.ID
 				payment.RobotID = &robotID
-				s.paymentRepo.Update(payment)
 			}
 		}
 	} else {
 		robotID = *payment.RobotID
 		// Ativar robô existente
-		robot, err := s.robotRepo.FindByID(robotID)
-		if err == nil && robot != nil {
+		robot, err := s.robotRepo.FindById(robotID)
+		if err != nil {
+			return err
+		}
+		if robot != nil {
 			robot.Status = models.StatusActive
-			s.robotRepo.Update(robot)
+			if err := s.robotRepo.UpdateBilling(robot); err != nil {
+				return err
+			}
 		}
 	}
 
-	// Criar assinatura se tiver subscription ID
+	// Criar assinatura se tiver subscription ID. Se falhar, o evento fica
+	// "failed" e o reenvio ou replay tenta de novo.
 	if session.Subscription != nil {
-		s.createSubscriptionRecord(session.Subscription.ID, payment.UserID, robotID)
+		if err := s.createSubscriptionRecord(session.Subscription.ID, payment.UserID, robotID, session.Metadata["plan_type"]); err != nil {
+			return fmt.Errorf("erro ao criar assinatura %s: %w", session.Subscription.ID, err)
+		}
 	}
 
 	return nil
@@ -287,40 +508,247 @@ func (s *StripeProvider) handleCheckoutSessionFailed(event stripe.Event) error {
 	return s.paymentRepo.Update(payment)
 }
 
+// handleInvoicePaymentSucceeded renova a assinatura a cada fatura paga:
+// move a assinatura para o novo período, estende a validade do robô e
+// registra o pagamento da fatura.
 func (s *StripeProvider) handleInvoicePaymentSucceeded(event stripe.Event) error {
-	// Lógica para renovação bem-sucedida
+	var invoice stripe.Invoice
+	if err := json.Unmarshal(event.Data.Raw, &invoice); err != nil {
+		return fmt.Errorf("erro ao fazer parse do evento: %w", err)
+	}
+
+	subscriptionID := invoiceSubscriptionID(&invoice)
+	if subscriptionID == "" {
+		// Fatura avulsa, sem assinatura associada
+		return nil
+	}
+
+	// A primeira fatura da assinatura já é tratada no checkout.session.completed
+	if invoice.BillingReason == stripe.InvoiceBillingReasonSubscriptionCreate {
+		return nil
+	}
+
+	subscription, err := s.subscriptionRepo.FindByProviderSubscriptionID(subscriptionID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar assinatura %s: %w", subscriptionID, err)
+	}
+	if subscription == nil {
+		return fmt.Errorf("%w: %s", ErrSubscriptionNotFound, subscriptionID)
+	}
+
+	if err := s.recordInvoicePayment(&invoice, subscription, models.PaymentCompleted); err != nil {
+		return err
+	}
+
+	periodStart, periodEnd := invoicePeriod(&invoice)
+	if periodEnd.IsZero() {
+		stripeSubscription, err := sub.Get(subscriptionID, nil)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar assinatura no Stripe: %w", err)
+		}
+		periodStart, periodEnd = subscriptionPeriod(stripeSubscription)
+	}
+
+	if periodEnd.After(subscription.CurrentPeriodEnd) {
+		subscription.CurrentPeriodStart = periodStart
+		subscription.CurrentPeriodEnd = periodEnd
+	}
+	// Encerra uma eventual inadimplência e reativa o robô suspenso
+	if err := s.subscriptionService.Reactivate(subscription); err != nil {
+		return err
+	}
+
+	robot, err := s.robotRepo.FindById(subscription.RobotID)
+	if err != nil {
+		return err
+	}
+	if robot == nil {
+		return fmt.Errorf("robô não encontrado para a assinatura: %s", subscriptionID)
+	}
+
+	if robot.PlanValidUntil == nil || subscription.CurrentPeriodEnd.After(*robot.PlanValidUntil) {
+		validUntil := subscription.CurrentPeriodEnd
+		robot.PlanValidUntil = &validUntil
+		return s.robotRepo.UpdateBilling(robot)
+	}
+
 	return nil
 }
 
+// handleInvoicePaymentFailed coloca a assinatura em inadimplência. O robô
+// continua funcionando durante o período de graça e só é suspenso pelo
+// worker de cobrança se nenhuma nova tentativa for paga até lá.
 func (s *StripeProvider) handleInvoicePaymentFailed(event stripe.Event) error {
-	// Lógica para falha na renovação
-	return nil
+	var invoice stripe.Invoice
+	if err := json.Unmarshal(event.Data.Raw, &invoice); err != nil {
+		return fmt.Errorf("erro ao fazer parse do evento: %w", err)
+	}
+
+	subscriptionID := invoiceSubscriptionID(&invoice)
+	if subscriptionID == "" {
+		return nil
+	}
+
+	subscription, err := s.subscriptionRepo.FindByProviderSubscriptionID(subscriptionID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar assinatura %s: %w", subscriptionID, err)
+	}
+	if subscription == nil {
+		return fmt.Errorf("%w: %s", ErrSubscriptionNotFound, subscriptionID)
+	}
+
+	if err := s.recordInvoicePayment(&invoice, subscription, models.PaymentFailed); err != nil {
+		return err
+	}
+
+	return s.subscriptionService.MarkPastDue(subscription)
 }
 
+// handleSubscriptionUpdated sincroniza status, período, plano e
+// agendamento de cancelamento feitos no Stripe com a assinatura local
 func (s *StripeProvider) handleSubscriptionUpdated(event stripe.Event) error {
-	// Lógica para atualização de assinatura
-	return nil
+	stripeSubscription, subscription, err := s.loadSubscriptionEvent(event)
+	if err != nil || subscription == nil {
+		return err
+	}
+
+	s.applyStripeSubscription(subscription, stripeSubscription)
+
+	switch status := localSubscriptionStatus(stripeSubscription.Status); status {
+	case models.SubscriptionActive:
+		if subscription.Status == models.SubscriptionPastDue {
+			if err := s.subscriptionService.Reactivate(subscription); err != nil {
+				return err
+			}
+		} else {
+			subscription.Status = status
+			if err := s.subscriptionRepo.Update(subscription); err != nil {
+				return err
+			}
+		}
+		return s.syncRobotWithSubscription(subscription)
+	case models.SubscriptionPastDue:
+		// O período de graça começa na primeira falha; se o invoice.payment_failed
+		// já chegou, a assinatura já está em past_due
+		if subscription.Status != models.SubscriptionPastDue {
+			return s.subscriptionService.MarkPastDue(subscription)
+		}
+		return s.subscriptionRepo.Update(subscription)
+	default:
+		subscription.Status = status
+		if err := s.subscriptionRepo.Update(subscription); err != nil {
+			return err
+		}
+		return s.syncRobotWithSubscription(subscription)
+	}
 }
 
+// handleSubscriptionDeleted encerra a assinatura local e desativa o robô
 func (s *StripeProvider) handleSubscriptionDeleted(event stripe.Event) error {
-	// Lógica para cancelamento de assinatura
-	return nil
+	stripeSubscription, subscription, err := s.loadSubscriptionEvent(event)
+	if err != nil || subscription == nil {
+		return err
+	}
+
+	s.applyStripeSubscription(subscription, stripeSubscription)
+
+	endedAt := time.Now()
+	if stripeSubscription.EndedAt > 0 {
+		endedAt = time.Unix(stripeSubscription.EndedAt, 0)
+	}
+	if subscription.CanceledAt == nil {
+		subscription.CanceledAt = &endedAt
+	}
+	subscription.Status = models.SubscriptionCanceled
+	subscription.CancelAtPeriodEnd = false
+	if err := s.subscriptionRepo.Update(subscription); err != nil {
+		return err
+	}
+
+	robot, err := s.robotRepo.FindById(subscription.RobotID)
+	if err != nil || robot == nil {
+		return err
+	}
+	if robot.Status != models.StatusSuspense {
+		robot.Status = models.StatusSuspense
+		robot.SuspendedReason = models.SuspendedForSubscription
+	}
+	if robot.PlanValidUntil == nil || robot.PlanValidUntil.After(endedAt) {
+		robot.PlanValidUntil = &endedAt
+	}
+	return s.robotRepo.UpdateBilling(robot)
 }
 
-// Métodos auxiliares
-func (s *StripeProvider) getPlanAmount(planType string) int64 {
-	amountMap := map[string]int64{
-		"basic":      2990, // R$ 29,90
-		"premium":    4990, // R$ 49,90
-		"enterprise": 9990, // R$ 99,90
+// loadSubscriptionEvent decodifica a assinatura do evento e busca o registro
+// local. Eventos mais antigos que o último já aplicado (o Stripe não garante
+// ordem de entrega) retornam assinatura nil e devem ser ignorados.
+func (s *StripeProvider) loadSubscriptionEvent(event stripe.Event) (*stripe.Subscription, *models.Subscription, error) {
+	var stripeSubscription stripe.Subscription
+	if err := json.Unmarshal(event.Data.Raw, &stripeSubscription); err != nil {
+		return nil, nil, fmt.Errorf("erro ao fazer parse do evento: %w", err)
+	}
+
+	subscription, err := s.subscriptionRepo.FindByProviderSubscriptionID(stripeSubscription.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar assinatura %s: %w", stripeSubscription.ID, err)
 	}
-	if amount, exists := amountMap[planType]; exists {
-		return amount
+	if subscription == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, stripeSubscription.ID)
 	}
-	return 2990 // default
+
+	eventAt := time.Unix(event.Created, 0)
+	if subscription.LastProviderEventAt != nil && eventAt.Before(*subscription.LastProviderEventAt) {
+		log.Printf("Evento %s fora de ordem ignorado para a assinatura %s\n", event.ID, stripeSubscription.ID)
+		return &stripeSubscription, nil, nil
+	}
+	subscription.LastProviderEventAt = &eventAt
+
+	return &stripeSubscription, subscription, nil
 }
 
-func (s *StripeProvider) createSubscriptionRecord(subscriptionID string, userID, robotID uuid.UUID) error {
+// syncRobotWithSubscription ajusta status e validade do robô conforme a assinatura
+func (s *StripeProvider) syncRobotWithSubscription(subscription *models.Subscription) error {
+	robot, err := s.robotRepo.FindById(subscription.RobotID)
+	if err != nil || robot == nil {
+		return err
+	}
+
+	switch subscription.Status {
+	case models.SubscriptionActive:
+		// Só desfaz suspensões causadas pela própria assinatura; as demais continuam
+		if robot.Status != models.StatusSuspense || robot.SuspendedReason == models.SuspendedForSubscription ||
+			robot.SuspendedReason == models.SuspendedForDunning {
+			robot.Status = models.StatusActive
+			robot.SuspendedReason = ""
+		}
+		if robot.PlanValidUntil == nil || subscription.CurrentPeriodEnd.After(*robot.PlanValidUntil) {
+			validUntil := subscription.CurrentPeriodEnd
+			robot.PlanValidUntil = &validUntil
+		}
+	case models.SubscriptionPending:
+		return nil
+	default:
+		// Um robô já suspenso por outro motivo mantém esse motivo
+		if robot.Status != models.StatusSuspense {
+			robot.Status = models.StatusSuspense
+			robot.SuspendedReason = models.SuspendedForSubscription
+		}
+	}
+
+	return s.robotRepo.UpdateBilling(robot)
+}
+
+// Métodos auxiliares
+func (s *StripeProvider) createSubscriptionRecord(subscriptionID string, userID, robotID uuid.UUID, planType string) error {
+	// Num reenvio do checkout a assinatura pode já ter sido gravada
+	existing, err := s.subscriptionRepo.FindByProviderSubscriptionID(subscriptionID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
 	// Buscar detalhes da assinatura no Stripe
 	stripe.Key = s.SecretKey
 	subscription, err := sub.Get(subscriptionID, nil)
@@ -328,17 +756,158 @@ func (s *StripeProvider) createSubscriptionRecord(subscriptionID string, userID,
 		return err
 	}
 
+	periodStart, periodEnd := subscriptionPeriod(subscription)
+
+	// O preço efetivamente assinado prevalece sobre o metadata do checkout
+	if plan, err := s.planCatalogService.FindByStripePriceID(subscriptionPriceID(subscription)); err == nil && plan != nil {
+		planType = string(plan.Code)
+	}
+
 	// Criar registro no banco
 	subscriptionRecord := &models.Subscription{
 		UserID:                 userID,
 		RobotID:                robotID,
-		PlanType:               models.BasicPlan, // Ajustar conforme necessário
+		PlanType:               models.PlanType(planType),
 		Status:                 models.SubscriptionActive,
-		CurrentPeriodStart:     time.Unix(subscription.CurrentPeriodStart, 0),
-		CurrentPeriodEnd:       time.Unix(subscription.CurrentPeriodEnd, 0),
+		CurrentPeriodStart:     periodStart,
+		CurrentPeriodEnd:       periodEnd,
 		ProviderSubscriptionID: subscriptionID,
 		ProviderCustomerID:     subscription.Customer.ID,
 	}
 
 	return s.subscriptionRepo.Create(subscriptionRecord)
 }
+
+// applyStripeSubscription copia período, plano e agendamento de cancelamento do Stripe
+func (s *StripeProvider) applyStripeSubscription(subscription *models.Subscription, stripeSubscription *stripe.Subscription) {
+	if periodStart, periodEnd := subscriptionPeriod(stripeSubscription); !periodEnd.IsZero() {
+		subscription.CurrentPeriodStart = periodStart
+		subscription.CurrentPeriodEnd = periodEnd
+	}
+
+	if plan, err := s.planCatalogService.FindByStripePriceID(subscriptionPriceID(stripeSubscription)); err == nil && plan != nil {
+		subscription.PlanType = plan.Code
+	}
+
+	subscription.CancelAtPeriodEnd = stripeSubscription.CancelAtPeriodEnd
+	if stripeSubscription.CanceledAt > 0 {
+		canceledAt := time.Unix(stripeSubscription.CanceledAt, 0)
+		subscription.CanceledAt = &canceledAt
+	} else {
+		subscription.CanceledAt = nil
+	}
+}
+
+// localSubscriptionStatus converte o status do Stripe para o status local
+func localSubscriptionStatus(status stripe.SubscriptionStatus) models.SubscriptionStatus {
+	switch status {
+	case stripe.SubscriptionStatusActive, stripe.SubscriptionStatusTrialing:
+		return models.SubscriptionActive
+	case stripe.SubscriptionStatusPastDue:
+		return models.SubscriptionPastDue
+	case stripe.SubscriptionStatusCanceled:
+		return models.SubscriptionCanceled
+	case stripe.SubscriptionStatusIncomplete:
+		return models.SubscriptionPending
+	case stripe.SubscriptionStatusIncompleteExpired:
+		return models.SubscriptionExpired
+	default: // unpaid, paused
+		return models.SubscriptionInactive
+	}
+}
+
+// subscriptionPriceID retorna o preço do item principal da assinatura
+func subscriptionPriceID(subscription *stripe.Subscription) string {
+	if subscription.Items == nil || len(subscription.Items.Data) == 0 || subscription.Items.Data[0].Price == nil {
+		return ""
+	}
+	return subscription.Items.Data[0].Price.ID
+}
+
+// recordInvoicePayment registra o pagamento de uma fatura uma única vez
+func (s *StripeProvider) recordInvoicePayment(invoice *stripe.Invoice, subscription *models.Subscription, status models.PaymentStatus) error {
+	if existing, err := s.paymentRepo.FindByProviderPaymentID(invoice.ID); err == nil && existing != nil {
+		if existing.Status == status {
+			return nil
+		}
+		existing.Status = status
+		return s.paymentRepo.Update(existing)
+	}
+
+	amount := invoice.AmountPaid
+	if status != models.PaymentCompleted {
+		amount = invoice.AmountDue
+	}
+
+	robotID := subscription.RobotID
+	payment := &models.Payment{
+		UserID:                 subscription.UserID,
+		RobotID:                &robotID,
+		Amount:                 amount,
+		Currency:               strings.ToUpper(string(invoice.Currency)),
+		Status:                 status,
+		Provider:               models.ProviderStripe,
+		ProviderPaymentID:      invoice.ID,
+		ProviderCustomerID:     subscription.ProviderCustomerID,
+		ProviderSubscriptionID: subscription.ProviderSubscriptionID,
+		Metadata:               fmt.Sprintf(`{"billing_reason":"%s","plan_type":"%s"}`, invoice.BillingReason, subscription.PlanType),
+	}
+
+	return s.paymentRepo.Create(payment)
+}
+
+// invoiceSubscriptionID retorna o ID da assinatura que gerou a fatura
+func invoiceSubscriptionID(invoice *stripe.Invoice) string {
+	if invoice.Parent == nil || invoice.Parent.SubscriptionDetails == nil || invoice.Parent.SubscriptionDetails.Subscription == nil {
+		return ""
+	}
+	return invoice.Parent.SubscriptionDetails.Subscription.ID
+}
+
+// invoicePeriod retorna o período de serviço cobrado pela fatura, a partir
+// das linhas da assinatura (o period_start/period_end da própria fatura se
+// refere ao período anterior).
+func invoicePeriod(invoice *stripe.Invoice) (time.Time, time.Time) {
+	var start, end int64
+	if invoice.Lines != nil {
+		for _, line := range invoice.Lines.Data {
+			if line.Period != nil && line.Period.End > end {
+				start, end = line.Period.Start, line.Period.End
+			}
+		}
+	}
+	if end == 0 {
+		return time.Time{}, time.Time{}
+	}
+	return time.Unix(start, 0), time.Unix(end, 0)
+}
+
+// parseWebhookSecrets aceita vários secrets separados por vírgula (rotação)
+func parseWebhookSecrets(raw string) []string {
+	var secrets []string
+	for _, secret := range strings.Split(raw, ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// parseWebhookTolerance lê a tolerância do timestamp em segundos
+func parseWebhookTolerance(raw string) time.Duration {
+	seconds, err := strconv.Atoi(raw)
+	if err != nil || seconds <= 0 {
+		return webhook.DefaultTolerance
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// subscriptionPeriod retorna o período corrente da assinatura. Desde a API
+// 2025-03-31 o Stripe expõe o período nos itens, e não mais na assinatura.
+func subscriptionPeriod(subscription *stripe.Subscription) (time.Time, time.Time) {
+	if subscription.Items == nil || len(subscription.Items.Data) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	item := subscription.Items.Data[0]
+	return time.Unix(item.CurrentPeriodStart, 0), time.Unix(item.CurrentPeriodEnd, 0)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+const defaultGracePeriodDays = 7
+
+var (
+	ErrSubscriptionNotFound      = errors.New("subscription not found")
+	ErrSubscriptionInactive      = errors.New("subscription is not active")
+	ErrSubscriptionNotCancelable = errors.New("subscription is already canceled or scheduled to cancel")
+	ErrSubscriptionNotResumable  = errors.New("subscription is not scheduled to cancel")
+	ErrInvalidPlanType           = errors.New("invalid plan type")
+	ErrSamePlan                  = errors.New("subscription is already on this plan")
+)
+
+type SubscriptionService interface {
+	FindByUserID(userID string) ([]models.Subscription, error)
+	FindByIDAndUserID(id, userID string) (*models.Subscription, error)
+	MarkPastDue(subscription *models.Subscription) error
+	Reactivate(subscription *models.Subscription) error
+	SuspendExpiredGracePeriods() (int, error)
+	RunDunningWorker(ctx context.Context, interval time.Duration)
+}
+
+type subscriptionService struct {
+	subscriptionRepo repository.SubscriptionRepository
+	robotRepo        repository.RobotRepository
+	gracePeriod      time.Duration
+}
+
+func NewSubscriptionService(subscriptionRepo repository.SubscriptionRepository, robotRepo repository.RobotRepository) SubscriptionService {
+	days, err := strconv.Atoi(os.Getenv("SUBSCRIPTION_GRACE_PERIOD_DAYS"))
+	if err != nil || days < 0 {
+		days = defaultGracePeriodDays
+	}
+	return &subscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		robotRepo:        robotRepo,
+		gracePeriod:      time.Duration(days) * 24 * time.Hour,
+	}
+}
+
+func (s *subscriptionService) FindByUserID(userID string) ([]models.Subscription, error) {
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.New("invalid user id")
+	}
+	return s.subscriptionRepo.FindByUserID(parsedUserID)
+}
+
+// FindByIDAndUserID busca uma assinatura garantindo que pertence ao usuário
+func (s *subscriptionService) FindByIDAndUserID(id, userID string) (*models.Subscription, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	subscription, err := s.subscriptionRepo.FindByID(parsedID)
+	if err != nil || subscription.UserID.String() != userID {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	return subscription, nil
+}
+
+// MarkPastDue coloca a assinatura em inadimplência. O período de graça é
+// contado a partir da primeira falha do ciclo; novas tentativas falhas do
+// Stripe apenas incrementam o contador.
+func (s *subscriptionService) MarkPastDue(subscription *models.Subscription) error {
+	now := time.Now()
+	if subscription.Status != models.SubscriptionPastDue || subscription.PastDueSince == nil {
+		graceEndsAt := now.Add(s.gracePeriod)
+		subscription.PastDueSince = &now
+		subscription.GracePeriodEndsAt = &graceEndsAt
+		subscription.FailedPaymentAttempts = 0
+	}
+	subscription.Status = models.SubscriptionPastDue
+	subscription.FailedPaymentAttempts++
+
+	return s.subscriptionRepo.Update(subscription)
+}
+
+// Reactivate encerra a inadimplência após um pagamento bem-sucedido e
+// reativa o robô caso ele tenha sido suspenso pela própria inadimplência.
+// Suspensões por outros motivos continuam valendo.
+func (s *subscriptionService) Reactivate(subscription *models.Subscription) error {
+	subscription.Status = models.SubscriptionActive
+	subscription.PastDueSince = nil
+	subscription.GracePeriodEndsAt = nil
+	subscription.FailedPaymentAttempts = 0
+	if err := s.subscriptionRepo.Update(subscription); err != nil {
+		return err
+	}
+
+	robot, err := s.robotRepo.FindById(subscription.RobotID)
+	if err != nil {
+		return err
+	}
+	if robot != nil && robot.Status == models.StatusSuspense && robot.SuspendedReason == models.SuspendedForDunning {
+		robot.Status = models.StatusActive
+		robot.SuspendedReason = ""
+		return s.robotRepo.UpdateBilling(robot)
+	}
+
+	return nil
+}
+
+// SuspendExpiredGracePeriods suspende os robôs cujas assinaturas seguem
+// inadimplentes após o fim do período de graça.
+func (s *subscriptionService) SuspendExpiredGracePeriods() (int, error) {
+	subscriptions, err := s.subscriptionRepo.FindGracePeriodExpired(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	suspended := 0
+	for _, subscription := range subscriptions {
+		robot, err := s.robotRepo.FindById(subscription.RobotID)
+		if err != nil {
+			return suspended, err
+		}
+		if robot == nil || robot.Status == models.StatusSuspense {
+			continue
+		}
+
+		robot.Status = models.StatusSuspense
+		robot.SuspendedReason = models.SuspendedForDunning
+		if err := s.robotRepo.UpdateBilling(robot); err != nil {
+			return suspended, err
+		}
+		suspended++
+	}
+
+	return suspended, nil
+}
+
+// RunDunningWorker executa SuspendExpiredGracePeriods periodicamente até o contexto ser cancelado
+func (s *subscriptionService) RunDunningWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if suspended, err := s.SuspendExpiredGracePeriods(); err != nil {
+			log.Printf("Erro ao verificar períodos de graça: %v\n", err)
+		} else if suspended > 0 {
+			log.Printf("%d robô(s) suspenso(s) por inadimplência\n", suspended)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
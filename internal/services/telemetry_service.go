@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/dtos"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+)
+
+var (
+	ErrInvalidTelemetryMetric = errors.New("metric must have 1 to 50 characters among a-z, 0-9, '_' and '.'")
+	ErrInvalidTelemetryQuery  = errors.New("from and to must be RFC3339 timestamps with from < to, and bucket a positive number of seconds")
+)
+
+const (
+	// Maior número de intervalos devolvidos numa consulta; períodos longos ganham intervalos maiores
+	telemetryMaxBuckets = 500
+	// Tolerância para relógios de robô adiantados
+	telemetryMaxClockSkew = 5 * time.Minute
+)
+
+var telemetryMetricPattern = regexp.MustCompile(`^[a-z0-9_.]{1,50}$`)
+
+// TelemetryService recebe as leituras dos robôs e as agrega segundo as regras do plano:
+// a retenção define por quantos dias os pontos ficam guardados e a resolução, o menor
+// intervalo de agregação que o dono pode consultar
+type TelemetryService interface {
+	Ingest(robotID uuid.UUID, input dtos.TelemetryBatchInputDTO) (*dtos.TelemetryIngestResponseDTO, error)
+	QueryForOwner(robotID, userID, metric, from, to, bucket string) (*dtos.TelemetrySeriesDTO, error)
+	DeleteExpired() (int64, error)
+	RunRetentionWorker(ctx context.Context, interval time.Duration)
+}
+
+type telemetryService struct {
+	repo         repository.TelemetryRepository
+	robotRepo    repository.RobotRepository
+	usageService UsageService
+}
+
+func NewTelemetryService(repo repository.TelemetryRepository, robotRepo repository.RobotRepository, usageService UsageService) TelemetryService {
+	return &telemetryService{repo: repo, robotRepo: robotRepo, usageService: usageService}
+}
+
+// Ingest grava o lote inteiro ou nada. Leituras mais antigas que a retenção do
+// plano (ou adiantadas demais) são descartadas e contadas em Dropped.
+func (s *telemetryService) Ingest(robotID uuid.UUID, input dtos.TelemetryBatchInputDTO) (*dtos.TelemetryIngestResponseDTO, error) {
+	plan, err := s.usageService.CurrentPlan(robotID)
+	if err != nil {
+		return nil, err
+	}
+	retention := telemetryRetention(plan)
+
+	now := time.Now()
+	points := make([]models.TelemetryPoint, 0, len(input.Points))
+	dropped := 0
+	for _, point := range input.Points {
+		if !telemetryMetricPattern.MatchString(point.Metric) {
+			return nil, ErrInvalidTelemetryMetric
+		}
+
+		recordedAt := now
+		if point.RecordedAt != nil {
+			recordedAt = *point.RecordedAt
+		}
+		expiresAt := recordedAt.Add(retention)
+		if !expiresAt.After(now) || recordedAt.After(now.Add(telemetryMaxClockSkew)) {
+			dropped++
+			continue
+		}
+
+		points = append(points, models.TelemetryPoint{
+			RobotID:    robotID,
+			Metric:     point.Metric,
+			Value:      *point.Value,
+			RecordedAt: recordedAt.UnixMilli(),
+			ExpiresAt:  expiresAt,
+		})
+	}
+
+	if len(points) > 0 {
+		if err := s.repo.CreateBatch(points); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dtos.TelemetryIngestResponseDTO{Accepted: len(points), Dropped: dropped}, nil
+}
+
+// QueryForOwner agrega uma métrica de um robô do usuário. Sem from/to, cobre as
+// últimas 24 horas; o período é cortado na retenção do plano atual e o intervalo
+// nunca fica abaixo da resolução do plano.
+func (s *telemetryService) QueryForOwner(robotID, userID, metric, from, to, bucket string) (*dtos.TelemetrySeriesDTO, error) {
+	if !telemetryMetricPattern.MatchString(metric) {
+		return nil, ErrInvalidTelemetryMetric
+	}
+
+	robot, err := s.robotRepo.FindByIDAndUserID(robotID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if robot == nil {
+		return nil, ErrRobotNotFound
+	}
+
+	plan, err := s.usageService.CurrentPlan(robot.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	end := now
+	if to != "" {
+		if end, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, ErrInvalidTelemetryQuery
+		}
+	}
+	start := end.Add(-24 * time.Hour)
+	if from != "" {
+		if start, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, ErrInvalidTelemetryQuery
+		}
+	}
+	if !start.Before(end) {
+		return nil, ErrInvalidTelemetryQuery
+	}
+	if oldest := now.Add(-telemetryRetention(plan)); start.Before(oldest) {
+		start = oldest
+	}
+
+	bucketSeconds := telemetryResolution(plan)
+	if bucket != "" {
+		requested, err := strconv.Atoi(bucket)
+		if err != nil || requested <= 0 {
+			return nil, ErrInvalidTelemetryQuery
+		}
+		if requested > bucketSeconds {
+			bucketSeconds = requested
+		}
+	}
+	// Limita a quantidade de intervalos devolvidos
+	if minimum := int(end.Sub(start).Seconds())/telemetryMaxBuckets + 1; bucketSeconds < minimum {
+		bucketSeconds = minimum
+	}
+
+	series := &dtos.TelemetrySeriesDTO{
+		RobotID:       robot.ID.String(),
+		Metric:        metric,
+		From:          start,
+		To:            end,
+		BucketSeconds: bucketSeconds,
+		Points:        []dtos.TelemetryBucketDTO{},
+	}
+	if !start.Before(end) {
+		// Período inteiro fora da retenção
+		return series, nil
+	}
+
+	buckets, err := s.repo.Aggregate(robot.ID, metric, start.UnixMilli(), end.UnixMilli(), int64(bucketSeconds)*1000)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range buckets {
+		series.Points = append(series.Points, dtos.TelemetryBucketDTO{
+			Time:  time.UnixMilli(b.Bucket).UTC(),
+			Avg:   b.Avg,
+			Min:   b.Min,
+			Max:   b.Max,
+			Count: b.Count,
+		})
+	}
+	return series, nil
+}
+
+// DeleteExpired apaga os pontos que passaram da retenção do plano em que foram gravados
+func (s *telemetryService) DeleteExpired() (int64, error) {
+	return s.repo.DeleteExpired(time.Now())
+}
+
+// RunRetentionWorker executa DeleteExpired periodicamente até o contexto ser cancelado
+func (s *telemetryService) RunRetentionWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.DeleteExpired(); err != nil {
+			log.Printf("Erro ao apagar telemetria vencida: %v\n", err)
+		} else if deleted > 0 {
+			log.Printf("%d ponto(s) de telemetria vencido(s) apagado(s)\n", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Planos antigos, sem os campos de telemetria preenchidos, usam os padrões do basic
+func telemetryRetention(plan *models.PlanCatalog) time.Duration {
+	days := plan.TelemetryRetentionDays
+	if days <= 0 {
+		days = 7
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func telemetryResolution(plan *models.PlanCatalog) int {
+	if plan.TelemetryResolutionSeconds <= 0 {
+		return 300
+	}
+	return plan.TelemetryResolutionSeconds
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/peruccii/roadmap-go-backend/internal/models"
+	"github.com/peruccii/roadmap-go-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrQuotaExceeded = errors.New("message quota exceeded")
+
+// MessageQuota é o consumo de mensagens de um robô no período de cobrança atual
+type MessageQuota struct {
+	Usage *models.MessageUsage
+	Limit int // 0 = ilimitado
+}
+
+// Exceeded indica se o robô já usou todas as mensagens do período
+func (q *MessageQuota) Exceeded() bool {
+	return q.Limit > 0 && q.Usage.MessagesUsed >= q.Limit
+}
+
+type UsageService interface {
+	CurrentQuota(robotID uuid.UUID) (*MessageQuota, error)
+	CurrentPlan(robotID uuid.UUID) (*models.PlanCatalog, error)
+	SubscriptionQuota(subscription *models.Subscription) (*MessageQuota, error)
+	// Reserve consome uma mensagem antes da chamada de IA; Release devolve se ela falhar
+	Reserve(quota *MessageQuota) (*models.QuotaReservation, error)
+	Release(reservation *models.QuotaReservation) error
+	ReleaseExpiredReservations() (int, error)
+	RunReservationSweeper(ctx context.Context, interval time.Duration)
+}
+
+type usageService struct {
+	usageRepo        repository.MessageUsageRepository
+	subscriptionRepo repository.SubscriptionRepository
+	planRepo         repository.PlanRepository
+	planCatalogRepo  repository.PlanCatalogRepository
+	reservationTTL   time.Duration
+}
+
+func NewUsageService(usageRepo repository.MessageUsageRepository, subscriptionRepo repository.SubscriptionRepository, planRepo repository.PlanRepository, planCatalogRepo repository.PlanCatalogRepository) UsageService {
+	return &usageService{
+		usageRepo:        usageRepo,
+		subscriptionRepo: subscriptionRepo,
+		planRepo:         planRepo,
+		planCatalogRepo:  planCatalogRepo,
+		reservationTTL:   time.Duration(envInt("QUOTA_RESERVATION_TTL_SECONDS", 300)) * time.Second,
+	}
+}
+
+// CurrentQuota resolve o plano e o período do robô a partir da assinatura ativa.
+// Robôs legados (sem assinatura, só PlanValidUntil) usam o mês corrente como período.
+func (s *usageService) CurrentQuota(robotID uuid.UUID) (*MessageQuota, error) {
+	subscription, err := s.subscriptionRepo.FindActiveByRobotID(robotID)
+	if err == nil {
+		return s.SubscriptionQuota(subscription)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return s.quota(robotID, s.legacyPlanType(robotID), periodStart, periodStart.AddDate(0, 1, 0))
+}
+
+// CurrentPlan retorna o plano do catálogo que vale para o robô agora, pela mesma
+// regra de CurrentQuota: a assinatura ativa ou, para robôs legados, o plano gravado
+func (s *usageService) CurrentPlan(robotID uuid.UUID) (*models.PlanCatalog, error) {
+	var planType models.PlanType
+	subscription, err := s.subscriptionRepo.FindActiveByRobotID(robotID)
+	switch {
+	case err == nil:
+		planType = subscription.PlanType
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		planType = s.legacyPlanType(robotID)
+	default:
+		return nil, err
+	}
+
+	plan, err := s.planCatalogRepo.FindByCode(string(planType))
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, ErrPlanNotFound
+	}
+	return plan, nil
+}
+
+func (s *usageService) legacyPlanType(robotID uuid.UUID) models.PlanType {
+	if plan, err := s.planRepo.FindByRobotID(robotID); err == nil {
+		return plan.Type
+	}
+	return models.BasicPlan
+}
+
+// SubscriptionQuota retorna o consumo do período atual da assinatura
+func (s *usageService) SubscriptionQuota(subscription *models.Subscription) (*MessageQuota, error) {
+	return s.quota(subscription.RobotID, subscription.PlanType, subscription.CurrentPeriodStart, subscription.CurrentPeriodEnd)
+}
+
+func (s *usageService) quota(robotID uuid.UUID, planType models.PlanType, periodStart, periodEnd time.Time) (*MessageQuota, error) {
+	plan, err := s.planCatalogRepo.FindByCode(string(planType))
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, ErrPlanNotFound
+	}
+
+	// Normaliza para UTC para que o mesmo período sempre gere a mesma chave
+	usage, err := s.usageRepo.FindOrCreate(robotID, periodStart.UTC(), periodEnd.UTC(), planType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MessageQuota{Usage: usage, Limit: plan.MessageLimit}, nil
+}
+
+// Reserve consome atomicamente uma mensagem da cota. A reserva vale por
+// reservationTTL: se não for confirmada nem liberada até lá (ex.: o processo caiu
+// no meio da chamada de IA), o sweeper devolve a mensagem.
+func (s *usageService) Reserve(quota *MessageQuota) (*models.QuotaReservation, error) {
+	reservation, err := s.usageRepo.Reserve(quota.Usage, quota.Limit, time.Now().Add(s.reservationTTL))
+	if err != nil {
+		return nil, err
+	}
+	if reservation == nil {
+		return nil, ErrQuotaExceeded
+	}
+	quota.Usage.MessagesUsed++
+	return reservation, nil
+}
+
+func (s *usageService) Release(reservation *models.QuotaReservation) error {
+	_, err := s.usageRepo.ReleaseReservation(reservation.ID)
+	return err
+}
+
+// ReleaseExpiredReservations devolve as mensagens de reservas pendentes vencidas
+func (s *usageService) ReleaseExpiredReservations() (int, error) {
+	reservations, err := s.usageRepo.FindExpiredReservations(time.Now(), 100)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, reservation := range reservations {
+		ok, err := s.usageRepo.ReleaseReservation(reservation.ID)
+		if err != nil {
+			return released, err
+		}
+		if ok {
+			released++
+		}
+	}
+	return released, nil
+}
+
+// RunReservationSweeper executa ReleaseExpiredReservations periodicamente até o contexto ser cancelado
+func (s *usageService) RunReservationSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if released, err := s.ReleaseExpiredReservations(); err != nil {
+			log.Printf("Erro ao liberar reservas de cota vencidas: %v\n", err)
+		} else if released > 0 {
+			log.Printf("%d reserva(s) de cota vencida(s) liberada(s)\n", released)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}